@@ -1,21 +1,159 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 
+	"github.com/jdefrancesco/squares/internal/bot"
 	"github.com/jdefrancesco/squares/internal/game"
+	"github.com/jdefrancesco/squares/internal/input"
+	"github.com/jdefrancesco/squares/internal/replay"
+	"github.com/jdefrancesco/squares/internal/scores"
+	"github.com/jdefrancesco/squares/internal/settings"
+	"github.com/jdefrancesco/squares/internal/sim"
 )
 
 func main() {
-	ebiten.SetWindowSize(game.ScreenWidth, game.ScreenHeight)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			os.Exit(verifyCmd(os.Args[2:]))
+		case "sim":
+			os.Exit(simCmd(os.Args[2:]))
+		}
+	}
+
+	seed := flag.Int64("seed", 0, "random seed for every run (default: a new seed per run)")
+	replayPath := flag.String("replay", "", "play back a recorded run instead of playing")
+	modeName := flag.String("mode", string(sim.ModeClassic), "movement mode: "+modeNames())
+	world := flag.Float64("world", 1, "make the world this many screens across, with a camera following the player")
+	deadzone := flag.Float64("deadzone", input.DefaultPadConfig.Deadzone, "gamepad stick deadzone, as a fraction of full tilt")
+	touchOffset := flag.Float64("touch-offset", -input.DefaultTouchConfig.OffsetY, "how far above a dragging finger to hold the player, in pixels")
+	botName := flag.String("bot", "", "let a bot play: "+strings.Join(bot.Names(), ", "))
+	configPath := flag.String("config", "", "tuning file to play with, reloaded live when it changes (default: built-in tuning)")
+	flag.Parse()
+
+	prefs, prefsPath := loadSettings()
+
+	var run ebiten.Game
+	if *replayPath != "" {
+		r, err := replay.Load(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if r.Rules != sim.RulesVersion {
+			log.Fatalf("%s was recorded with rules v%d; this build plays v%d", *replayPath, r.Rules, sim.RulesVersion)
+		}
+		p := game.NewPlayback(r)
+		p.UseSettings(prefs)
+		run = p
+	} else {
+		g := game.New()
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "seed" {
+				g = game.NewWithSeed(*seed)
+			}
+		})
+		mode, err := sim.ParseMode(*modeName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.UseSettings(prefs, prefsPath)
+		g.SetMode(mode)
+		if *world < 1 {
+			log.Fatalf("-world must be at least 1, got %v", *world)
+		}
+		g.SetWorldScale(*world)
+		if *deadzone < 0 || *deadzone >= 1 {
+			log.Fatalf("-deadzone must be in [0, 1), got %v", *deadzone)
+		}
+		g.ConfigureGamepad(input.PadConfig{Deadzone: *deadzone})
+		g.ConfigureTouch(input.TouchConfig{OffsetY: -*touchOffset})
+		if *configPath != "" {
+			t, err := sim.LoadTuning(*configPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			g.SetTuning(t)
+			g.WatchTuning(*configPath)
+		}
+		if *botName != "" {
+			p, err := bot.New(*botName, g.Seed())
+			if err != nil {
+				log.Fatal(err)
+			}
+			g.SetBot(p)
+		} else {
+			// Only human runs count toward the personal best and the
+			// high scores.
+			if dir, err := replay.DefaultDir(); err == nil {
+				g.SaveReplaysTo(dir)
+			} else {
+				log.Printf("replays will not be saved: %v", err)
+			}
+			if t, err := openScores(); t != nil {
+				if err != nil {
+					log.Print(err)
+				}
+				g.KeepScores(t)
+			} else {
+				log.Printf("high scores will not be saved: %v", err)
+			}
+		}
+		run = g
+	}
+
+	game.SetupWindow(prefs)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("Squares")
 
-	if err := ebiten.RunGame(game.New()); err != nil {
+	if err := ebiten.RunGame(run); err != nil {
 		if err == ebiten.Termination {
 			return
 		}
 		log.Fatal(err)
 	}
 }
+
+// openScores opens the high-score table. It can return a usable table
+// along with an error about what happened to the old one.
+func openScores() (*scores.Table, error) {
+	path, err := scores.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return scores.Open(path, scores.DefaultLimit)
+}
+
+// loadSettings reads the player's settings and where to save changes to
+// them, which is nowhere if the file there should not be overwritten.
+func loadSettings() (settings.Settings, string) {
+	path, err := settings.DefaultPath()
+	if err != nil {
+		log.Printf("settings will not be saved: %v", err)
+		return settings.Default(), ""
+	}
+	s, err := settings.Load(path)
+	switch {
+	case errors.Is(err, settings.ErrCorrupt):
+		log.Print(err)
+	case err != nil:
+		log.Printf("settings will not be saved: %v", err)
+		return s, ""
+	}
+	return s, path
+}
+
+// modeNames lists the modes for flag help.
+func modeNames() string {
+	var names []string
+	for _, m := range sim.Modes() {
+		names = append(names, string(m))
+	}
+	return strings.Join(names, ", ")
+}
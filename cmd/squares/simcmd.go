@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jdefrancesco/squares/internal/batch"
+	"github.com/jdefrancesco/squares/internal/bot"
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// simCmd plays a batch of headless bot games and reports statistics.
+func simCmd(args []string) int {
+	fs := flag.NewFlagSet("sim", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: squares sim [flags]")
+		fs.PrintDefaults()
+	}
+	botName := fs.String("bot", "avoider", "bot policy: "+strings.Join(bot.Names(), ", "))
+	modeName := fs.String("mode", string(sim.ModeClassic), "movement mode: "+modeNames())
+	runs := fs.Int("n", 100, "number of games")
+	seed := fs.Int64("seed", 1, "seed of the first game; game i uses seed+i")
+	maxSecs := fs.Float64("max", 600, "stop games the bot survives this many seconds (0: no limit, so a bot that never dies runs forever)")
+	csvPath := fs.String("csv", "", "write per-run results as CSV to this file")
+	jsonPath := fs.String("json", "", "write the summary and per-run results as JSON to this file")
+	configPath := fs.String("config", "", "tuning file to play with (default: built-in tuning)")
+	stress := fs.Int("stress", 0, "stress test: keep at least this many entities in play")
+	world := fs.Float64("world", 1, "make the world this many screens across, with a camera following the player")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *world < 1 {
+		fmt.Fprintf(os.Stderr, "-world must be at least 1, got %v\n", *world)
+		return 2
+	}
+	if *maxSecs < 0 {
+		fmt.Fprintf(os.Stderr, "-max must not be negative, got %v\n", *maxSecs)
+		return 2
+	}
+
+	mode, err := sim.ParseMode(*modeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var tuning *sim.Tuning
+	if *configPath != "" {
+		t, err := sim.LoadTuning(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		tuning = &t
+	}
+
+	results, err := batch.Play(batch.Config{
+		Bot:       *botName,
+		Mode:      mode,
+		Runs:      *runs,
+		FirstSeed: *seed,
+		MaxTicks:  int(*maxSecs * sim.TickRate),
+		Width:     sim.DefaultWidth * *world,
+		Height:    sim.DefaultHeight * *world,
+		Tuning:    tuning,
+
+		ViewWidth:   sim.DefaultWidth,
+		ViewHeight:  sim.DefaultHeight,
+		MinEntities: *stress,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	summary := batch.Summarize(*botName, results)
+
+	if err := batch.WriteText(os.Stdout, summary); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *csvPath != "" {
+		if err := writeFile(*csvPath, func(f *os.File) error { return batch.WriteCSV(f, results) }); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if *jsonPath != "" {
+		if err := writeFile(*jsonPath, func(f *os.File) error { return batch.WriteJSON(f, summary, results) }); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func writeFile(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
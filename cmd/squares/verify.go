@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jdefrancesco/squares/internal/replay"
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// verifyCmd re-simulates each replay and checks it against its claimed
+// result. Replays played with custom tuning fail unless allowed. It exits
+// 1 if any replay fails and 2 on usage errors.
+func verifyCmd(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	allowCustom := fs.Bool("allow-custom-tuning", false, "pass replays played with custom or live-reloaded tuning if they re-simulate to their claims")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: squares verify <replay>...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, path := range fs.Args() {
+		if !verifyFile(os.Stdout, path, *allowCustom) {
+			status = 1
+		}
+	}
+	return status
+}
+
+func verifyFile(out io.Writer, path string, allowCustom bool) bool {
+	r, err := replay.Load(path)
+	if err != nil {
+		fmt.Fprintf(out, "%s: FAIL: %v\n", path, err)
+		return false
+	}
+
+	got, err := replay.Verify(r)
+	tuning := "built-in tuning"
+	if r.Tuning != sim.DefaultTuning() {
+		tuning = "custom tuning"
+	}
+	fmt.Fprintf(out, "%s: seed %d, %s mode, rules v%d, %s, %d live tuning changes\n",
+		path, r.Seed, r.Mode, r.Rules, tuning, len(r.Changes))
+	fmt.Fprintf(out, "  %-10s %14s %14s\n", "", "claimed", "simulated")
+	fmt.Fprintf(out, "  %-10s %14d %14d\n", "score", r.Result.Score, got.Score)
+	fmt.Fprintf(out, "  %-10s %14s %14s\n", "time", formatTicks(r.Result.Ticks), formatTicks(got.Ticks))
+	fmt.Fprintf(out, "  %-10s %14.2f %14.2f\n", "max size", r.Result.MaxSize, got.MaxSize)
+	fmt.Fprintf(out, "  %-10s %14s %14s\n", "death", r.Result.Cause, got.Cause)
+	if err != nil {
+		fmt.Fprintf(out, "  FAIL: %v\n", err)
+		return false
+	}
+	if err := replay.Standard(r); err != nil && !allowCustom {
+		fmt.Fprintf(out, "  FAIL: %v (-allow-custom-tuning checks it anyway)\n", err)
+		return false
+	}
+	fmt.Fprintln(out, "  OK")
+	return true
+}
+
+func formatTicks(ticks int) string {
+	return fmt.Sprintf("%.2fs", float64(ticks)/sim.TickRate)
+}
@@ -0,0 +1,265 @@
+// Package batch plays many headless games with a bot and summarizes them,
+// so balance changes can be judged on numbers instead of feel.
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/jdefrancesco/squares/internal/bot"
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// Config describes a batch: Runs games seeded FirstSeed, FirstSeed+1, ...
+type Config struct {
+	Bot       string
+	Mode      sim.Mode
+	Runs      int
+	FirstSeed int64
+	// MaxTicks ends runs the bot survives this long. Zero means no limit.
+	MaxTicks int
+
+	Width, Height float64
+	// ViewWidth and ViewHeight are the camera's view; see sim.Options.
+	ViewWidth, ViewHeight float64
+	// Tuning is the balance under test. Nil means sim.DefaultTuning.
+	Tuning *sim.Tuning
+	// MinEntities keeps the arena crowded; see sim.Options.
+	MinEntities int
+}
+
+// Run is the outcome of one game.
+type Run struct {
+	Seed     int64     `json:"seed"`
+	Survival float64   `json:"survival"`
+	Score    int       `json:"score"`
+	MaxSize  float64   `json:"max_size"`
+	Cause    sim.Cause `json:"-"`
+	Death    string    `json:"death"`
+	Pickups  int       `json:"pickups"`
+}
+
+// Play runs every game in cfg, spread across all CPUs. Results are in
+// seed order regardless of which finished first.
+func Play(cfg Config) ([]Run, error) {
+	if _, err := bot.New(cfg.Bot, 0); err != nil {
+		return nil, err
+	}
+	if cfg.Runs <= 0 {
+		return nil, fmt.Errorf("batch: need at least one run, got %d", cfg.Runs)
+	}
+	if cfg.MaxTicks < 0 {
+		return nil, fmt.Errorf("batch: max ticks must not be negative, got %d", cfg.MaxTicks)
+	}
+
+	runs := make([]Run, cfg.Runs)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(cfg.Runs, runtime.GOMAXPROCS(0)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				runs[i] = playOne(cfg, cfg.FirstSeed+int64(i))
+			}
+		}()
+	}
+	for i := range runs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return runs, nil
+}
+
+func playOne(cfg Config, seed int64) Run {
+	p, _ := bot.New(cfg.Bot, seed)
+	w := sim.New(sim.Options{
+		Seed:        seed,
+		Mode:        cfg.Mode,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		ViewWidth:   cfg.ViewWidth,
+		ViewHeight:  cfg.ViewHeight,
+		Tuning:      cfg.Tuning,
+		MinEntities: cfg.MinEntities,
+	})
+
+	pickups := 0
+	for !w.Over() && (cfg.MaxTicks == 0 || w.Ticks() < cfg.MaxTicks) {
+		w.Step(p.Act(bot.Observe(w)))
+		for _, ev := range w.Events() {
+			if ev.Kind == sim.EventBoost {
+				pickups++
+			}
+		}
+	}
+
+	res := w.Result()
+	return Run{
+		Seed:     seed,
+		Survival: float64(res.Ticks) / sim.TickRate,
+		Score:    res.Score,
+		MaxSize:  res.MaxSize,
+		Cause:    res.Cause,
+		Death:    deathLabel(res.Cause),
+		Pickups:  pickups,
+	}
+}
+
+// deathLabel names a cause for reports; runs cut off by MaxTicks survived.
+func deathLabel(c sim.Cause) string {
+	if c == sim.CauseNone {
+		return "survived"
+	}
+	return c.String()
+}
+
+// Dist summarizes the distribution of one metric.
+type Dist struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P10  float64 `json:"p10"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	Max  float64 `json:"max"`
+}
+
+func newDist(vals []float64) Dist {
+	if len(vals) == 0 {
+		return Dist{}
+	}
+	s := append([]float64(nil), vals...)
+	sort.Float64s(s)
+
+	sum := 0.0
+	for _, v := range s {
+		sum += v
+	}
+	return Dist{
+		Min:  s[0],
+		Mean: sum / float64(len(s)),
+		P10:  quantile(s, 0.10),
+		P50:  quantile(s, 0.50),
+		P90:  quantile(s, 0.90),
+		Max:  s[len(s)-1],
+	}
+}
+
+// quantile interpolates the q-th quantile of sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := min(lo+1, len(sorted)-1)
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// Summary aggregates a batch.
+type Summary struct {
+	Bot      string         `json:"bot"`
+	Runs     int            `json:"runs"`
+	Survival Dist           `json:"survival"`
+	Score    Dist           `json:"score"`
+	MaxSize  Dist           `json:"max_size"`
+	Pickups  Dist           `json:"pickups"`
+	Deaths   map[string]int `json:"deaths"`
+}
+
+func Summarize(botName string, runs []Run) Summary {
+	var survival, score, maxSize, pickups []float64
+	deaths := map[string]int{}
+	for _, r := range runs {
+		survival = append(survival, r.Survival)
+		score = append(score, float64(r.Score))
+		maxSize = append(maxSize, r.MaxSize)
+		pickups = append(pickups, float64(r.Pickups))
+		deaths[r.Death]++
+	}
+	return Summary{
+		Bot:      botName,
+		Runs:     len(runs),
+		Survival: newDist(survival),
+		Score:    newDist(score),
+		MaxSize:  newDist(maxSize),
+		Pickups:  newDist(pickups),
+		Deaths:   deaths,
+	}
+}
+
+// WriteText prints s as a human-readable table.
+func WriteText(w io.Writer, s Summary) error {
+	_, err := fmt.Fprintf(w, "%d runs of %s\n\n%-10s %9s %9s %9s %9s %9s %9s\n",
+		s.Runs, s.Bot, "", "min", "mean", "p10", "p50", "p90", "max")
+	if err != nil {
+		return err
+	}
+	rows := []struct {
+		name string
+		d    Dist
+	}{
+		{"survival", s.Survival},
+		{"score", s.Score},
+		{"max size", s.MaxSize},
+		{"pickups", s.Pickups},
+	}
+	for _, row := range rows {
+		d := row.d
+		_, err := fmt.Fprintf(w, "%-10s %9.2f %9.2f %9.2f %9.2f %9.2f %9.2f\n",
+			row.name, d.Min, d.Mean, d.P10, d.P50, d.P90, d.Max)
+		if err != nil {
+			return err
+		}
+	}
+
+	causes := make([]string, 0, len(s.Deaths))
+	for c := range s.Deaths {
+		causes = append(causes, c)
+	}
+	sort.Strings(causes)
+	if _, err := fmt.Fprintln(w, "\ndeaths"); err != nil {
+		return err
+	}
+	for _, c := range causes {
+		n := s.Deaths[c]
+		pct := 100 * float64(n) / float64(max(s.Runs, 1))
+		if _, err := fmt.Fprintf(w, "  %-14s %6d %6.1f%%\n", c, n, pct); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes one row per run.
+func WriteCSV(w io.Writer, runs []Run) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"seed", "survival", "score", "max_size", "death", "pickups"})
+	for _, r := range runs {
+		cw.Write([]string{
+			strconv.FormatInt(r.Seed, 10),
+			strconv.FormatFloat(r.Survival, 'f', 3, 64),
+			strconv.Itoa(r.Score),
+			strconv.FormatFloat(r.MaxSize, 'f', 3, 64),
+			r.Death,
+			strconv.Itoa(r.Pickups),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the summary along with every run.
+func WriteJSON(w io.Writer, s Summary, runs []Run) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Summary Summary `json:"summary"`
+		Runs    []Run   `json:"runs"`
+	}{s, runs})
+}
@@ -0,0 +1,102 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+func testConfig() Config {
+	return Config{Bot: "avoider", Runs: 8, FirstSeed: 100, MaxTicks: 60 * sim.TickRate, Width: 640, Height: 480}
+}
+
+func TestPlayIsDeterministicAndOrdered(t *testing.T) {
+	a, err := Play(testConfig())
+	if err != nil {
+		t.Fatalf("play: %v", err)
+	}
+	b, _ := Play(testConfig())
+
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("run %d differs between batches: %+v vs %+v", i, a[i], b[i])
+		}
+		if a[i].Seed != 100+int64(i) {
+			t.Fatalf("run %d has seed %d, want %d", i, a[i].Seed, 100+i)
+		}
+	}
+}
+
+func TestPlayRejectsBadConfig(t *testing.T) {
+	cfg := testConfig()
+	cfg.Bot = "nope"
+	if _, err := Play(cfg); err == nil {
+		t.Fatalf("expected an unknown bot to fail")
+	}
+	cfg = testConfig()
+	cfg.Runs = 0
+	if _, err := Play(cfg); err == nil {
+		t.Fatalf("expected zero runs to fail")
+	}
+	cfg = testConfig()
+	cfg.MaxTicks = -1
+	if _, err := Play(cfg); err == nil {
+		t.Fatalf("expected a negative tick limit to fail")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	runs := []Run{
+		{Survival: 10, Score: 1, Death: "hazard circle"},
+		{Survival: 20, Score: 2, Death: "larger square"},
+		{Survival: 30, Score: 3, Death: "larger square"},
+		{Survival: 40, Score: 4, Death: "survived"},
+		{Survival: 50, Score: 5, Death: "larger square"},
+	}
+	s := Summarize("test", runs)
+
+	if s.Survival.Min != 10 || s.Survival.Max != 50 || s.Survival.Mean != 30 || s.Survival.P50 != 30 {
+		t.Fatalf("unexpected survival distribution: %+v", s.Survival)
+	}
+	if s.Survival.P90 != 46 {
+		t.Fatalf("expected interpolated p90=46, got %v", s.Survival.P90)
+	}
+	if s.Deaths["larger square"] != 3 || s.Deaths["hazard circle"] != 1 || s.Deaths["survived"] != 1 {
+		t.Fatalf("unexpected deaths: %v", s.Deaths)
+	}
+}
+
+func TestWriters(t *testing.T) {
+	runs, _ := Play(testConfig())
+	s := Summarize("avoider", runs)
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, runs); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading csv back: %v", err)
+	}
+	if len(rows) != len(runs)+1 || rows[0][0] != "seed" {
+		t.Fatalf("expected header plus %d rows, got %d", len(runs), len(rows))
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, s, runs); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var got struct {
+		Summary Summary `json:"summary"`
+		Runs    []Run   `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("reading json back: %v", err)
+	}
+	if got.Summary.Runs != len(runs) || len(got.Runs) != len(runs) {
+		t.Fatalf("json lost runs: %+v", got.Summary)
+	}
+}
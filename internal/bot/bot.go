@@ -0,0 +1,126 @@
+// Package bot drives the game without a human. A Policy looks at an
+// Observation of the world each tick and picks the input for that tick.
+//
+// Built-in policies steer with a movement vector rather than snapping to a
+// target, so they are held to the same top speed as keyboard and stick
+// players instead of teleporting around the arena.
+package bot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// Policy chooses the input for the next tick.
+type Policy interface {
+	Act(obs Observation) sim.Input
+}
+
+// Observation is what a policy gets to see of the world.
+type Observation struct {
+	// The player can move in the Width by Height area whose top-left
+	// corner is (MinX, MinY).
+	MinX, MinY    float64
+	Width, Height float64
+	Tick          int
+
+	Player sim.Entity
+	// Entities is owned by the world; policies must not keep it.
+	Entities []sim.Entity
+
+	DashCooldownLeft float64
+	DashInvLeft      float64
+	InvincibleLeft   float64
+}
+
+// Observe captures the current state of w.
+func Observe(w *sim.World) Observation {
+	x0, y0, x1, y1 := w.Bounds()
+	return Observation{
+		MinX:             x0,
+		MinY:             y0,
+		Width:            x1 - x0,
+		Height:           y1 - y0,
+		Tick:             w.Ticks(),
+		Player:           w.Player(),
+		Entities:         w.Entities(),
+		DashCooldownLeft: w.DashCooldownLeft(),
+		DashInvLeft:      w.DashInvLeft(),
+		InvincibleLeft:   w.InvincibleLeft(),
+	}
+}
+
+// Center is the middle of the area the player can move in.
+func (o Observation) Center() (float64, float64) {
+	return o.MinX + o.Width/2, o.MinY + o.Height/2
+}
+
+// Invulnerable reports whether touching anything is currently safe.
+func (o Observation) Invulnerable() bool {
+	return o.InvincibleLeft > 0 || o.DashInvLeft > 0
+}
+
+// Edible reports whether running into e would eat it.
+func (o Observation) Edible(e sim.Entity) bool {
+	return e.Kind == sim.KindSquare && (o.Invulnerable() || o.Player.Size > e.Size)
+}
+
+// Threat reports whether running into e would end the run.
+func (o Observation) Threat(e sim.Entity) bool {
+	if o.Invulnerable() {
+		return false
+	}
+	return e.Kind == sim.KindCircleHazard || (e.Kind == sim.KindSquare && e.Size >= o.Player.Size)
+}
+
+// Run plays a headless game under p until it ends or maxTicks have run,
+// and returns the finished world.
+func Run(p Policy, opts sim.Options, maxTicks int) *sim.World {
+	w := sim.New(opts)
+	for w.Ticks() < maxTicks && !w.Over() {
+		w.Step(p.Act(Observe(w)))
+	}
+	return w
+}
+
+var builtins = map[string]func(seed int64) Policy{
+	"greedy":  func(int64) Policy { return Greedy{} },
+	"avoider": func(int64) Policy { return Avoider{} },
+	"random":  func(seed int64) Policy { return NewRandom(seed) },
+}
+
+// New returns the built-in policy called name. Policies that make random
+// choices draw them from seed.
+func New(name string, seed int64) (Policy, error) {
+	mk, ok := builtins[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bot %q (have %v)", name, Names())
+	}
+	return mk(seed), nil
+}
+
+// Names lists the built-in policies.
+func Names() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// steer returns an input moving from the player toward (x, y), easing off
+// on the final few pixels so the player doesn't jitter around the target.
+func steer(o Observation, x, y float64) sim.Input {
+	dx := x - o.Player.X
+	dy := y - o.Player.Y
+	d := math.Hypot(dx, dy)
+	if d < 1 {
+		return sim.Input{}
+	}
+	k := math.Min(1, d/20) / d
+	return sim.Input{MoveX: dx * k, MoveY: dy * k}
+}
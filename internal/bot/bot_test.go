@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+func testOptions(seed int64) sim.Options {
+	return sim.Options{Seed: seed, Width: 640, Height: 480}
+}
+
+func TestBuiltinsAreDeterministic(t *testing.T) {
+	for _, name := range Names() {
+		a, _ := New(name, 5)
+		b, _ := New(name, 5)
+		ra := Run(a, testOptions(11), 20*sim.TickRate).Result()
+		rb := Run(b, testOptions(11), 20*sim.TickRate).Result()
+		if ra != rb {
+			t.Fatalf("%s: runs diverged: %+v vs %+v", name, ra, rb)
+		}
+	}
+}
+
+func TestNewRejectsUnknownBot(t *testing.T) {
+	if _, err := New("nope", 0); err == nil {
+		t.Fatalf("expected an error for an unknown bot")
+	}
+}
+
+func TestGreedyEats(t *testing.T) {
+	total := 0
+	for seed := int64(1); seed <= 5; seed++ {
+		total += Run(Greedy{}, testOptions(seed), 30*sim.TickRate).Score()
+	}
+	if total == 0 {
+		t.Fatalf("expected greedy to eat something across 5 runs")
+	}
+}
+
+func TestAvoiderMovesAwayFromHazard(t *testing.T) {
+	o := Observation{
+		Width:  640,
+		Height: 480,
+		Player: sim.Entity{Kind: sim.KindSquare, X: 320, Y: 240, Size: 22},
+		Entities: []sim.Entity{
+			{Kind: sim.KindCircleHazard, X: 360, Y: 240, Size: 20},
+		},
+	}
+	in := Avoider{}.Act(o)
+	if in.MoveX >= 0 {
+		t.Fatalf("expected to move left, away from the hazard, got %+v", in)
+	}
+}
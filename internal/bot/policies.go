@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// Greedy chases the nearest thing it can eat and ignores everything else.
+// It is a baseline: a balance change that makes Greedy score much higher
+// has probably made food too easy.
+type Greedy struct{}
+
+func (Greedy) Act(o Observation) sim.Input {
+	best, bestD := -1, math.Inf(1)
+	for i, e := range o.Entities {
+		if !o.Edible(e) {
+			continue
+		}
+		if d := math.Hypot(e.X-o.Player.X, e.Y-o.Player.Y); d < bestD {
+			best, bestD = i, d
+		}
+	}
+	if best < 0 {
+		cx, cy := o.Center()
+		return steer(o, cx, cy)
+	}
+	e := o.Entities[best]
+	return steer(o, e.X, e.Y)
+}
+
+const (
+	// How far ahead Avoider extrapolates threats, in seconds.
+	avoidLookahead = 0.25
+	// Threats further than this (edge to edge, in px) are ignored.
+	avoidRadius = 140.0
+	// Avoider dashes through a threat it can no longer outrun.
+	avoidDashGap = 10.0
+)
+
+// Avoider keeps away from anything that can kill it, drifting back toward
+// the arena center when nothing is near. It never goes looking for food.
+type Avoider struct{}
+
+func (Avoider) Act(o Observation) sim.Input {
+	p := o.Player
+
+	var fx, fy float64
+	nearest := math.Inf(1)
+	for _, e := range o.Entities {
+		if !o.Threat(e) {
+			continue
+		}
+		ex := e.X + e.VX*avoidLookahead
+		ey := e.Y + e.VY*avoidLookahead
+		dx, dy := p.X-ex, p.Y-ey
+		d := math.Hypot(dx, dy)
+		gap := d - (p.Size+e.Size)/2
+		nearest = math.Min(nearest, gap)
+		if gap > avoidRadius || d < 1e-9 {
+			continue
+		}
+		w := 1 / math.Max(gap, 1)
+		fx += dx / d * w
+		fy += dy / d * w
+	}
+
+	// Walls are threats too: getting pinned in a corner is how avoiders die.
+	const wall = 60.0
+	fx += wallPush(p.X-o.MinX, o.Width, wall)
+	fy += wallPush(p.Y-o.MinY, o.Height, wall)
+
+	if fx == 0 && fy == 0 {
+		cx, cy := o.Center()
+		return steer(o, cx, cy)
+	}
+	d := math.Hypot(fx, fy)
+	in := sim.Input{MoveX: fx / d, MoveY: fy / d}
+	in.Dash = nearest < avoidDashGap && o.DashCooldownLeft <= 0
+	return in
+}
+
+// wallPush returns a push away from whichever edge of [0, size] v is
+// within margin of, growing as it gets closer.
+func wallPush(v, size, margin float64) float64 {
+	switch {
+	case v < margin:
+		return 1/math.Max(v, 1) - 1/margin
+	case v > size-margin:
+		return -(1/math.Max(size-v, 1) - 1/margin)
+	}
+	return 0
+}
+
+// Random wanders in a new direction every second or so and dashes at
+// random. It is the floor any real policy should beat.
+type Random struct {
+	rng    *rand.Rand
+	mx, my float64
+	next   int
+}
+
+func NewRandom(seed int64) *Random {
+	return &Random{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (r *Random) Act(o Observation) sim.Input {
+	if o.Tick >= r.next {
+		a := r.rng.Float64() * 2 * math.Pi
+		r.mx, r.my = math.Cos(a), math.Sin(a)
+		r.next = o.Tick + 30 + r.rng.Intn(60)
+	}
+	return sim.Input{MoveX: r.mx, MoveY: r.my, Dash: r.rng.Intn(200) == 0}
+}
@@ -0,0 +1,265 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jdefrancesco/squares/internal/input"
+	"github.com/jdefrancesco/squares/internal/ui"
+)
+
+// bindingsScene rebinds the keys and buttons of every action, then goes
+// back to the settings. Each action has a row of slots; picking one waits
+// for the next key, mouse button or gamepad button and binds it there. A
+// control that already does something wanted at the same time is refused
+// with a warning, and pressing it again moves it. Escape and Delete cancel
+// and clear the slot after a moment, unless pressed again to bind them.
+// Changes apply at once and are saved on the way out.
+type bindingsScene struct {
+	back    scene
+	changed bool
+
+	// The slot waiting for a control, and how long it has left.
+	waiting bool
+	action  input.Action
+	slot    int
+	left    float64
+	// clash is the control last refused for clashing.
+	clash input.Control
+	// armed is Escape or Delete, pressed once and waiting to cancel or
+	// clear unless it is pressed again.
+	armed input.Control
+	// note tells the player what their last press did.
+	note string
+}
+
+const (
+	// bindingSlots is how many controls each action shows.
+	bindingSlots = 4
+	// bindingWait is how long a slot waits for a control, so a player
+	// with only a touchscreen is not stuck waiting.
+	bindingWait = 5.0
+	// bindingArmed is how long Escape or Delete waits for a second press
+	// before cancelling or clearing.
+	bindingArmed = 1.5
+
+	bindingsWidth = 560
+	bindingLabelW = 96
+)
+
+// actionLabels name the actions for the player.
+var actionLabels = map[input.Action]string{
+	input.Dash:    "Dash",
+	input.Pause:   "Pause",
+	input.Restart: "Restart",
+	input.Quit:    "Quit",
+	input.Up:      "Up",
+	input.Down:    "Down",
+	input.Left:    "Left",
+	input.Right:   "Right",
+	input.Confirm: "Confirm",
+	input.Back:    "Back",
+	input.Click:   "Click",
+}
+
+// padLabels are the gamepad buttons as Xbox pads label them, which most
+// players know.
+var padLabels = map[input.PadButton]string{
+	input.PadSouth:         "A",
+	input.PadEast:          "B",
+	input.PadWest:          "X",
+	input.PadNorth:         "Y",
+	input.PadLeftShoulder:  "LB",
+	input.PadRightShoulder: "RB",
+	input.PadBack:          "Back",
+	input.PadStart:         "Start",
+	input.PadUp:            "Up",
+	input.PadDown:          "Down",
+	input.PadLeft:          "Left",
+	input.PadRight:         "Right",
+}
+
+// controlLabel is how c is shown to the player.
+func controlLabel(l language, c input.Control) string {
+	switch c.Device {
+	case input.Mouse:
+		return l.tr("Mouse") + " " + c.Name
+	case input.Pad:
+		b, _ := input.ParsePadButton(c.Name)
+		return l.tr("Pad") + " " + padLabels[b]
+	}
+	return c.Name
+}
+
+// actionsLabel names every action in a.
+func actionsLabel(l language, a input.Action) string {
+	var out []string
+	for _, act := range input.Actions() {
+		if a.Has(act) {
+			out = append(out, l.tr(actionLabels[act]))
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+// keysFor names the keys bound to a, for hints, or its other controls if
+// it has no keys. It is empty if nothing is bound to a.
+func (g *Game) keysFor(a input.Action) string {
+	b := g.settings.Bindings
+	cs := b.On(a, input.Keyboard)
+	if len(cs) == 0 {
+		cs = b[a]
+	}
+	return strings.Join(names(cs, func(c input.Control) string { return controlLabel(g.look.lang, c) }), " / ")
+}
+
+func newBindingsScene(back scene) *bindingsScene {
+	return &bindingsScene{back: back}
+}
+
+func (s *bindingsScene) enter(g *Game) {}
+
+func (s *bindingsScene) exit(g *Game) {
+	if s.changed {
+		g.saveSettings()
+	}
+}
+
+func (s *bindingsScene) update(g *Game, in ui.Input, frame float64) error {
+	if s.waiting {
+		s.listen(g, frame)
+		// Whatever was pressed was for binding, not for the menu.
+		in = ui.Input{}
+	}
+
+	u := g.ui
+	u.Begin(in, g.screen())
+	defer u.End()
+
+	u.Panel(u.Screen())
+	l := g.look.lang
+	row := u.RowHeight()
+	actions := input.Actions()
+	h := 2*row + ui.StackHeight(len(actions)+2, row, menuGap) + menuGap
+	st := ui.VStack(ui.Centered(u.Screen(), bindingsWidth, h), menuGap)
+	u.Heading(st.Row(2*row), l.tr("KEYS AND BUTTONS"), 2)
+	u.Hint(st.Row(row), s.hint(l))
+
+	b := g.settings.Bindings
+	for _, a := range actions {
+		r := st.Row(row)
+		u.Label(ui.Rect{X: r.X, Y: r.Y, W: bindingLabelW, H: r.H}, l.tr(actionLabels[a]))
+		slots := ui.Rect{X: r.X + bindingLabelW, Y: r.Y, W: r.W - bindingLabelW, H: r.H}.Columns(bindingSlots, menuGap)
+		for i, sr := range slots {
+			text := "-"
+			if i < len(b[a]) {
+				c := b[a][i]
+				text = controlLabel(l, c)
+				if b.Conflicts(a, c) != 0 {
+					text = "! " + text
+				}
+			}
+			if s.waiting && s.action == a && s.slot == i {
+				text = "..."
+			}
+			if u.Button(fmt.Sprintf("%v-%d", a, i), sr, text) {
+				s.wait(a, min(i, len(b[a])))
+			}
+		}
+	}
+
+	buttons := st.Row(row).Columns(2, menuGap)
+	if u.Button("reset", buttons[0], l.tr("Reset all")) {
+		s.rebind(g, input.DefaultBindings())
+		s.note = l.tr("Every action is back to its defaults.")
+	}
+	if u.Button("back", buttons[1], l.tr("Back")) || u.Pressed(input.Back) || u.Pressed(input.Pause) {
+		g.goTo(s.back)
+	}
+	return nil
+}
+
+// hint is the line under the heading: what the last press did, or what
+// to do next.
+func (s *bindingsScene) hint(l language) string {
+	switch {
+	case s.note != "":
+		return s.note
+	case s.waiting:
+		return l.trf("Press a key or button for %s. Esc cancels, Delete clears.", l.tr(actionLabels[s.action]))
+	}
+	return l.tr("Pick a slot to bind it. ! marks a clash.")
+}
+
+// wait starts waiting for a control to put in slot of a.
+func (s *bindingsScene) wait(a input.Action, slot int) {
+	s.waiting, s.action, s.slot, s.left = true, a, slot, bindingWait
+	s.clash, s.armed, s.note = input.Control{}, input.Control{}, ""
+}
+
+// listen binds the control pressed this frame, if any, to the waiting
+// slot. Escape gives up and Delete empties the slot, once they have had a
+// moment to be pressed again and bound instead.
+func (s *bindingsScene) listen(g *Game, frame float64) {
+	l := g.look.lang
+	s.left -= frame
+	c, ok := justPressed()
+	switch {
+	case !ok:
+		if s.left > 0 {
+			return
+		}
+		s.waiting, s.note = false, ""
+		// A Delete pressed again was meant for binding, even if it was
+		// refused.
+		del := s.armed == input.Key("Delete") && s.clash != s.armed
+		if cs := g.settings.Bindings[s.action]; del && s.slot < len(cs) {
+			s.rebind(g, g.settings.Bindings.Without(s.action, cs[s.slot]))
+		}
+	case c == input.Key("Escape") && s.armed != c:
+		s.armed, s.left = c, bindingArmed
+		s.note = l.tr("Cancelling. Press Esc again to bind it.")
+	case c == input.Key("Delete") && s.armed != c:
+		s.armed, s.left = c, bindingArmed
+		s.note = l.tr("Clearing the slot. Press Delete again to bind it.")
+	default:
+		s.bind(g, c)
+	}
+}
+
+// bind puts c in the waiting slot, unless it clashes and was not pressed
+// twice, in which case it says so and keeps waiting.
+func (s *bindingsScene) bind(g *Game, c input.Control) {
+	l := g.look.lang
+	b := g.settings.Bindings
+	a := s.action
+	if slices.Contains(b[a], c) {
+		s.waiting, s.note = false, ""
+		return
+	}
+	clash := b.Conflicts(a, c)
+	if clash != 0 && c != s.clash {
+		s.clash, s.left = c, bindingWait
+		s.note = l.trf("%s is already %s. Press it again to move it here.", controlLabel(l, c), actionsLabel(l, clash))
+		return
+	}
+	s.waiting, s.note = false, ""
+	if clash != 0 {
+		s.note = l.trf("%s moved from %s.", controlLabel(l, c), actionsLabel(l, clash))
+	}
+	s.rebind(g, b.Without(clash, c).Set(a, s.slot, c))
+}
+
+// rebind switches to bindings b.
+func (s *bindingsScene) rebind(g *Game, b input.Bindings) {
+	next := g.settings
+	next.Bindings = b
+	g.changeSettings(next)
+	s.changed = true
+}
+
+func (s *bindingsScene) draw(g *Game, c canvas) {
+	drawWorld(c, g.view(), g.world, &g.trail, g.look)
+	g.ui.Draw(uiPainter{c})
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/jdefrancesco/squares/internal/ui"
+)
+
+// canvas draws onto an image through a uniform scale and offset, so callers
+// work in their own units: logical pixels for the HUD, world units for the
+// arena. A point (x, y) lands on pixel (x*scale+offX, y*scale+offY).
+type canvas struct {
+	dst        *ebiten.Image
+	scale      float64
+	offX, offY float64
+}
+
+// screenCanvas draws on screen in logical pixels, given how many of them
+// span its width. On high-DPI displays screen has more pixels than that.
+func screenCanvas(screen *ebiten.Image, logicalW float64) canvas {
+	return canvas{dst: screen, scale: float64(screen.Bounds().Dx()) / logicalW}
+}
+
+// size is the extent of the destination in canvas units.
+func (c canvas) size() (float64, float64) {
+	b := c.dst.Bounds()
+	return float64(b.Dx()) / c.scale, float64(b.Dy()) / c.scale
+}
+
+// within returns a canvas whose origin is at (x, y) on this one and whose
+// unit is scale of this one's.
+func (c canvas) within(x, y, scale float64) canvas {
+	return canvas{
+		dst:   c.dst,
+		scale: c.scale * scale,
+		offX:  c.offX + x*c.scale,
+		offY:  c.offY + y*c.scale,
+	}
+}
+
+func (c canvas) pt(x, y float64) (float64, float64) {
+	return x*c.scale + c.offX, y*c.scale + c.offY
+}
+
+func (c canvas) fill(col color.Color) {
+	c.dst.Fill(col)
+}
+
+func (c canvas) rect(x, y, w, h float64, col color.Color) {
+	px, py := c.pt(x, y)
+	vector.DrawFilledRect(c.dst, float32(px), float32(py), float32(w*c.scale), float32(h*c.scale), col, false)
+}
+
+// square draws a square centered on (x, y), turned by angle radians.
+func (c canvas) square(x, y, size, angle float64, col color.RGBA) {
+	px, py := c.pt(x, y)
+	if angle == 0 {
+		drawSquareAA(c.dst, px, py, size*c.scale, col)
+		return
+	}
+	drawRotatedSquare(c.dst, px, py, size*c.scale, angle, col)
+}
+
+func (c canvas) circle(x, y, r float64, col color.RGBA) {
+	px, py := c.pt(x, y)
+	drawFilledCircle(c.dst, float32(px), float32(py), float32(r*c.scale), col)
+}
+
+func (c canvas) ring(x, y, r, thickness float64, col color.RGBA) {
+	px, py := c.pt(x, y)
+	drawRing(c.dst, float32(px), float32(py), float32(r*c.scale), float32(thickness*c.scale), col)
+}
+
+// text draws s with its baseline starting at (x, y), scaling the HUD font
+// along with everything else.
+func (c canvas) text(s string, x, y int, col color.Color) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(c.scale, c.scale)
+	op.GeoM.Translate(c.pt(float64(x), float64(y)))
+	op.ColorScale.ScaleWithColor(col)
+	text.DrawWithOptions(c.dst, s, hudFace, op)
+}
+
+// uiPainter draws the menus' widgets on a canvas in logical pixels.
+type uiPainter struct {
+	c canvas
+}
+
+func (p uiPainter) FillRect(r ui.Rect, col color.Color) {
+	p.c.rect(r.X, r.Y, r.W, r.H, col)
+}
+
+func (p uiPainter) Text(s string, x, y, scale float64, col color.Color) {
+	p.c.within(x, y, scale).text(s, 0, hudFace.Metrics().Ascent.Ceil(), col)
+}
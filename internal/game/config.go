@@ -1,22 +1,48 @@
 package game
 
+import (
+	"time"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
 const (
 	// Control size of window. The bigger the less challeging in general.
 	// ScreenWidth  = 800
 	// ScreenHeight = 600
-	ScreenWidth  = 640
-	ScreenHeight = 480
+	// This is only the starting size: the window can be resized, and each
+	// run's arena fills the window it starts in.
+	ScreenWidth  = sim.DefaultWidth
+	ScreenHeight = sim.DefaultHeight
 
-	dashCooldown    = 0.90
-	dashInvDuration = 0.25
+	// Upper bound on simulation ticks run per Update. After a long stall
+	// (window drag, debugger) we drop the backlog instead of fast-forwarding
+	// through it.
+	maxTicksPerUpdate = 8
 
-	// Might make this more dynamic. Giving different durations invincible.
-	invincibleDuration = 2.5
 	invinciblePopupDur = 1.2
 
-	maxSpawnsWithoutEdible = 6
-	playerRotationRate     = 6.0
+	// Dash after-images fade out over trailDuration seconds, starting at
+	// trailAlpha opacity.
+	trailDuration = 0.2
+	trailAlpha    = 110
+
+	// How long tuning reload messages stay up. Errors get longer to read.
+	toastDuration      = 2.5
+	errorToastDuration = 8.0
+
+	// Seconds a finished bot run stays on screen before the next starts.
+	botRestartDelay = 3.0
+
+	// Scene changes fade out and back in over this many seconds each way.
+	fadeDuration = 0.15
+
+	// The game-over screen ignores input for this long after it appears.
+	gameOverGrace = 0.6
 
-	growthScale = 0.05
-	growthFlat  = 0.8
+	// Gamepad rumble: a tick for each square eaten, a thump on death.
+	rumbleEat      = 0.25
+	rumbleEatDur   = 60 * time.Millisecond
+	rumbleDeath    = 1.0
+	rumbleDeathDur = 400 * time.Millisecond
 )
@@ -1,248 +1,470 @@
+// Package game is the Ebiten front end for Squares. It reads input, steps a
+// sim.World on a fixed tick and draws the result.
 package game
 
 import (
-	"image/color"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
 	"math"
+	"path/filepath"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"github.com/jdefrancesco/squares/internal/bot"
+	"github.com/jdefrancesco/squares/internal/input"
+	"github.com/jdefrancesco/squares/internal/replay"
+	"github.com/jdefrancesco/squares/internal/scores"
+	"github.com/jdefrancesco/squares/internal/settings"
+	"github.com/jdefrancesco/squares/internal/sim"
+	"github.com/jdefrancesco/squares/internal/ui"
 )
 
 type Game struct {
-	player Entity
-	angle  float64
-
-	score    int
-	gameOver bool
+	seed      int64
+	fixedSeed bool
+	mode      sim.Mode
+	tuning    *sim.Tuning
+
+	// The arena is worldScale windows across; above 1 the camera scrolls.
+	worldScale float64
+
+	world *sim.World
+	win   window
+	input *input.Reader
+	pad   *input.Gamepad
+	touch *input.TouchSource
+
+	// The player's settings, saved to settingsPath when changed on the
+	// settings screen, and what they make of input and drawing. padCfg is
+	// the gamepad setup before the settings' sensitivity.
+	settings     settings.Settings
+	settingsPath string
+	ctl          controls
+	look         look
+	padCfg       input.PadConfig
+
+	// When policy is set it plays instead of the human, and a finished run
+	// restarts on its own after botRestartDelay.
+	policy bot.Policy
+
+	// The scene being shown, and the one being faded to, if any. fade is
+	// how far the fade has got, in seconds.
+	scene scene
+	next  scene
+	fade  float64
+	// ui runs the menus of whichever scene is showing.
+	ui *ui.Context
+
+	// Every run is recorded. Finished runs are written to replayDir, if
+	// set, as the last run and, when it beats it, the personal best.
+	rec       *replay.Recorder
+	replayDir string
+	newBest   bool
+
+	// Finished runs are entered into scores, if set. rank is where the
+	// last one placed, or -1.
+	scores *scores.Table
+	rank   int
+
+	popupText string
+	popupLeft float64
+	trail     dashTrail
+
+	// A watched tuning file is reloaded when it changes and swapped in at
+	// the next tick. The toast reports how that went.
+	watch         *tuningWatcher
+	pendingTuning *sim.Tuning
+	toast         string
+	toastErr      bool
+	toastLeft     float64
+
+	// Wall-clock time not yet consumed by fixed simulation ticks.
+	accum      float64
+	dashQueued bool
+	last       time.Time
+	now        func() time.Time
+
+	// saved is set once the run's replay is written, so leaving a run
+	// that is over does not write it again.
+	saved bool
+}
 
-	ents       []Entity
-	spawnTimer float64
+// New returns a game seeded from the clock. Each restart picks a new seed.
+func New() *Game {
+	return newGame(0, false)
+}
 
-	elapsed           float64
-	spawnsSinceEdible int
+// NewWithSeed returns a game whose runs all start from seed, including
+// runs started by restarting after a game over.
+func NewWithSeed(seed int64) *Game {
+	return newGame(seed, true)
+}
 
-	dashCDLeft   float64
-	dashInvLeft  float64
-	prevMouseBtn bool
+func newGame(seed int64, fixedSeed bool) *Game {
+	g := &Game{now: time.Now, seed: seed, fixedSeed: fixedSeed, worldScale: 1, win: newWindow()}
+	g.padCfg = input.DefaultPadConfig
+	g.pad = input.NewGamepad(ebitenPads{}, g.padCfg)
+	g.pad.OnChange = g.padChanged
+	g.touch = input.NewTouchSource(ebitenTouches{&g.win}, input.DefaultTouchConfig)
+	g.input = input.NewReader(defaultInput(&g.win, &g.ctl, g.pad, g.touch))
+	g.ui = ui.New(hudFace)
+	g.settings = settings.Default()
+	g.configure()
+	g.reset()
+	g.setScene(newTitleScene())
+	return g
+}
 
-	invincibleLeft float64
-	popupText      string
-	popupLeft      float64
+// Seed reports the seed of the current run.
+func (g *Game) Seed() int64 {
+	return g.seed
+}
 
-	paused     bool
-	prevPKey   bool
-	prevEscKey bool
-	prevQKey   bool
+// SetMode switches to a different movement mode, starting a fresh run.
+func (g *Game) SetMode(m sim.Mode) {
+	g.mode = m
+	g.reset()
+}
 
-	last time.Time
+// SetWorldScale makes the arena scale windows across, with the camera
+// following the player, starting a fresh run.
+func (g *Game) SetWorldScale(scale float64) {
+	g.worldScale = scale
+	g.reset()
 }
 
-func New() *Game {
-	g := &Game{}
+// SetTuning switches to a different balance, starting a fresh run.
+func (g *Game) SetTuning(t sim.Tuning) {
+	g.tuning = &t
 	g.reset()
-	return g
 }
 
-func (g *Game) reset() {
-	g.player = Entity{
-		kind: KindSquare,
-		x:    ScreenWidth / 2,
-		y:    ScreenHeight / 2,
-		size: 22,
-		col:  color.RGBA{35, 145, 85, 255},
-	}
-	g.angle = 0
-	g.score = 0
-	g.gameOver = false
+// WatchTuning reloads the tuning file at path whenever it changes and
+// applies it to the running game without restarting. A file that fails to
+// load is reported on screen and the current tuning is kept.
+func (g *Game) WatchTuning(path string) {
+	g.watch = newTuningWatcher(path, g.now())
+}
+
+// ConfigureGamepad changes how gamepad sticks are read. The settings
+// still pick the sensitivity.
+func (g *Game) ConfigureGamepad(cfg input.PadConfig) {
+	g.padCfg = cfg
+	g.configure()
+}
+
+// ConfigureTouch changes how far from the finger a touch drag puts the
+// player.
+func (g *Game) ConfigureTouch(cfg input.TouchConfig) {
+	g.touch.Configure(cfg)
+}
 
-	g.ents = nil
-	g.spawnTimer = 0
+// SetBot hands control of the player to p and skips the title screen. The
+// keyboard still pauses and quits.
+func (g *Game) SetBot(p bot.Policy) {
+	g.policy = p
+	g.setScene(newPlayScene(false))
+}
 
-	g.elapsed = 0
-	g.spawnsSinceEdible = 0
+// SaveReplaysTo makes the game write finished runs into dir.
+func (g *Game) SaveReplaysTo(dir string) {
+	g.replayDir = dir
+}
 
-	g.dashCDLeft = 0
-	g.dashInvLeft = 0
-	g.prevMouseBtn = false
+// KeepScores enters every finished run into t.
+func (g *Game) KeepScores(t *scores.Table) {
+	g.scores = t
+}
+
+// reset starts a new run with a view the size of the window, saving the
+// replay of the one it replaces.
+func (g *Game) reset() {
+	g.abandon()
+	if !g.fixedSeed {
+		g.seed = g.now().UnixNano()
+	}
+	vw, vh := math.Round(g.win.w), math.Round(g.win.h)
+	opts := sim.Options{
+		Seed:       g.seed,
+		Mode:       g.mode,
+		Width:      vw * g.worldScale,
+		Height:     vh * g.worldScale,
+		ViewWidth:  vw,
+		ViewHeight: vh,
+		Tuning:     g.tuning,
+	}
+	g.world = sim.New(opts)
+	g.rec = replay.NewRecorder(opts)
+	g.pendingTuning = nil
+	g.newBest = false
+	g.rank = -1
 
-	g.invincibleLeft = 0
 	g.popupText = ""
 	g.popupLeft = 0
+	g.trail.reset()
 
-	g.paused = false
-	g.prevPKey = false
-	g.prevEscKey = false
-	g.prevQKey = false
-
-	g.last = time.Now()
+	g.accum = 0
+	g.dashQueued = false
+	g.saved = false
+	g.last = g.now()
 }
 
 func (g *Game) Update() error {
-	now := time.Now()
-	dt := now.Sub(g.last).Seconds()
-	if dt > 0.05 {
-		dt = 0.05
-	}
+	now := g.now()
+	frame := now.Sub(g.last).Seconds()
 	g.last = now
 
-	qDown := ebiten.IsKeyPressed(ebiten.KeyQ)
-	pressedQuitKey := qDown && !g.prevQKey
-	g.prevQKey = qDown
-	if pressedQuitKey {
+	g.toastLeft = max(0, g.toastLeft-frame)
+	g.pollTuning(now)
+
+	toggled := toggleFullscreen()
+	if toggled {
+		g.settings.Fullscreen = !g.settings.Fullscreen
+		g.saveSettings()
+	}
+	g.touch.SetButtons(g.touchButtons())
+	f := g.input.Next()
+	if toggled {
+		// The keys were for the window, not for whatever they are bound
+		// to, such as Enter confirming the focused button.
+		b := g.settings.Bindings
+		f.Pressed &^= b.Actions(input.Key("Enter")) | b.Actions(input.Key("F11"))
+	}
+	if (f.Pressed.Has(input.Quit) && !g.takingKeys()) || ebiten.IsWindowBeingClosed() {
+		g.abandon()
 		return ebiten.Termination
 	}
-
-	if g.gameOver {
-		if ebiten.IsKeyPressed(ebiten.KeyR) {
-			g.reset()
-		}
-		return nil
+	err := g.updateScene(uiInput(f), frame)
+	if err == ebiten.Termination {
+		g.abandon()
 	}
+	return err
+}
 
-	mx, my := ebiten.CursorPosition()
-	mx = clamp(mx, 0, ScreenWidth-1)
-	my = clamp(my, 0, ScreenHeight-1)
-
-	mouseDown := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
-	clicked := mouseDown && !g.prevMouseBtn
-	g.prevMouseBtn = mouseDown
-
-	pDown := ebiten.IsKeyPressed(ebiten.KeyP)
-	escDown := ebiten.IsKeyPressed(ebiten.KeyEscape)
-	pressedPauseKey := (pDown && !g.prevPKey) || (escDown && !g.prevEscKey)
-	g.prevPKey = pDown
-	g.prevEscKey = escDown
-
-	if pressedPauseKey {
-		g.paused = !g.paused
-		g.last = now
-		return nil
+// takingKeys reports whether the scene wants every key for itself, so
+// none of them are shortcuts.
+func (g *Game) takingKeys() bool {
+	if b, ok := g.scene.(*bindingsScene); ok && b.waiting {
+		return true
 	}
+	return g.ui.Typing()
+}
 
-	if g.paused {
-		g.last = now
-		return nil
+// advance feeds frame seconds of wall-clock time into the accumulator and
+// runs as many fixed ticks as it covers, returning how many ran. A dash
+// press is held until the next tick actually runs so it is never dropped.
+// With a bot in control, it picks the input of every tick instead and the
+// player's dashes are dropped.
+func (g *Game) advance(frame float64, in sim.Input) int {
+	g.dashQueued = g.dashQueued || in.Dash
+	in.Dash = false
+
+	g.accum += frame
+	if limit := maxTicksPerUpdate * sim.TickDT; g.accum > limit {
+		g.accum = limit
 	}
 
-	g.elapsed += dt
-
-	if g.dashCDLeft > 0 {
-		g.dashCDLeft = math.Max(0, g.dashCDLeft-dt)
-	}
-	if g.dashInvLeft > 0 {
-		g.dashInvLeft = math.Max(0, g.dashInvLeft-dt)
+	ticks := 0
+	for g.accum >= sim.TickDT && !g.world.Over() {
+		g.accum -= sim.TickDT
+		tick := in
+		if g.policy != nil {
+			tick = g.policy.Act(bot.Observe(g.world))
+		} else {
+			tick.Dash = g.dashQueued
+		}
+		g.dashQueued = false
+		g.step(tick)
+		ticks++
 	}
-	if g.invincibleLeft > 0 {
-		g.invincibleLeft = math.Max(0, g.invincibleLeft-dt)
+	return ticks
+}
+
+// pollTuning reloads the watched tuning file if it changed.
+func (g *Game) pollTuning(now time.Time) {
+	if g.watch == nil || !g.watch.changed(now) {
+		return
 	}
-	if g.popupLeft > 0 {
-		g.popupLeft = math.Max(0, g.popupLeft-dt)
+	t, err := sim.LoadTuning(g.watch.path)
+	if err != nil {
+		g.showToast(err.Error()+"\nKeeping the previous tuning.", true)
+		return
 	}
+	g.tuning = &t
+	g.pendingTuning = &t
+	g.showToast("Reloaded "+filepath.Base(g.watch.path), false)
+}
 
-	g.player.x = float64(mx)
-	g.player.y = float64(my)
+func (g *Game) padChanged(id int, connected bool) {
+	if connected {
+		g.showToast(fmt.Sprintf("Gamepad %d connected", id+1), false)
+	} else {
+		g.showToast(fmt.Sprintf("Gamepad %d disconnected", id+1), false)
+	}
+}
 
-	if clicked && g.dashCDLeft <= 0 {
-		g.dashCDLeft = dashCooldown
-		g.dashInvLeft = dashInvDuration
+func (g *Game) showToast(msg string, isErr bool) {
+	g.toast = msg
+	g.toastErr = isErr
+	g.toastLeft = toastDuration
+	if isErr {
+		g.toastLeft = errorToastDuration
 	}
+}
 
-	g.angle += playerRotationRate * dt
+// step runs one simulation tick and reacts to what happened in it.
+func (g *Game) step(in sim.Input) {
+	if g.pendingTuning != nil {
+		g.world.SetTuning(*g.pendingTuning)
+		g.rec.ChangeTuning(*g.pendingTuning)
+		g.pendingTuning = nil
+	}
 
-	difficulty := 0.12*g.elapsed + 0.8*float64(g.score)
-	spawnInterval := clamp(0.85-0.0035*difficulty, 0.25, 0.85)
+	g.world.Step(in)
+	g.rec.Record(in)
+	g.trail.update(g.world)
+	if g.world.Over() {
+		r := g.rec.Finish(g.world.Result())
+		g.saveLast(r)
+		// A run under other tuning could have been made easier, so it
+		// counts for neither the best nor the high scores.
+		if replay.Standard(r) == nil {
+			g.saveBest(r)
+			g.enterScore()
+		}
+	}
 
-	g.spawnTimer += dt
-	for g.spawnTimer >= spawnInterval {
-		g.spawnTimer -= spawnInterval
-		g.spawnEntityWithDifficulty(difficulty)
+	if g.popupLeft > 0 {
+		g.popupLeft = max(0, g.popupLeft-sim.TickDT)
+	}
+	for _, ev := range g.world.Events() {
+		switch ev.Kind {
+		case sim.EventBoost:
+			g.popupText = "INVINCIBLE"
+			g.popupLeft = invinciblePopupDur
+		case sim.EventAte:
+			g.rumble(rumbleEat, rumbleEatDur)
+		case sim.EventDied:
+			g.rumble(rumbleDeath, rumbleDeathDur)
+		}
 	}
+}
 
-	playerHit := g.player
-	playerHit.size *= 0.90
+// rumble vibrates the player's gamepads, unless a bot is playing.
+func (g *Game) rumble(strength float64, d time.Duration) {
+	if g.policy == nil {
+		g.pad.Rumble(strength, d)
+	}
+}
 
-	inv := (g.invincibleLeft > 0) || (g.dashInvLeft > 0)
+// abandon saves the replay of a run that was started and is being left
+// unfinished, by restarting, quitting to the title or quitting the game.
+// It is only the last run: it never reached a result to beat the best with.
+func (g *Game) abandon() {
+	if g.world != nil && g.world.Ticks() > 0 && !g.saved {
+		g.saveLast(g.rec.Finish(g.world.Result()))
+	}
+}
 
-	alive := g.ents[:0]
-	for _, e := range g.ents {
-		e.x += e.vx * dt
-		e.y += e.vy * dt
+// saveLast writes r as the last run.
+func (g *Game) saveLast(r *replay.Replay) {
+	g.saved = true
+	if g.replayDir == "" {
+		return
+	}
+	if err := replay.Save(filepath.Join(g.replayDir, "last"+replay.Ext), r); err != nil {
+		log.Printf("saving replay: %v", err)
+	}
+}
 
-		if e.x < -160 || e.x > ScreenWidth+160 || e.y < -160 || e.y > ScreenHeight+160 {
-			continue
-		}
+// saveBest writes the finished run r as the personal best if it beats the
+// stored one.
+func (g *Game) saveBest(r *replay.Replay) {
+	if g.replayDir == "" {
+		return
+	}
+	bestPath := filepath.Join(g.replayDir, "best"+replay.Ext)
+	best, err := replay.Load(bestPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("reading best replay: %v", err)
+	}
+	if best != nil && best.Result.Score >= r.Result.Score {
+		return
+	}
+	if err := replay.Save(bestPath, r); err != nil {
+		log.Printf("saving best replay: %v", err)
+		return
+	}
+	g.newBest = true
+}
 
-		switch e.kind {
-		case KindSquare:
-			if squareIntersectsSquare(playerHit, e) {
-				if inv || g.player.size > e.size {
-					g.score++
-					g.player.size += growthScale*e.size + growthFlat
-					g.spawnsSinceEdible = 0
-					continue
-				}
-				g.gameOver = true
-			}
-
-		case KindCircleHazard:
-			if circleIntersectsSquare(e, playerHit) {
-				g.gameOver = true
-			}
-
-		case KindCircleBoost:
-			if circleIntersectsSquare(e, playerHit) {
-				g.invincibleLeft = invincibleDuration
-				g.dashInvLeft = 0
-				g.popupText = "INVINCIBLE"
-				g.popupLeft = invinciblePopupDur
-				continue
-			}
-		}
+// enterScore puts the finished run in the high-score table.
+func (g *Game) enterScore() {
+	if g.scores == nil {
+		return
+	}
+	e := scores.NewEntry(g.world.Result(), g.seed, g.world.Mode(), g.now())
+	rank, err := g.scores.Add(e)
+	if err != nil {
+		log.Printf("saving high scores: %v", err)
+	}
+	g.rank = rank
+}
 
-		alive = append(alive, e)
+func (g *Game) Draw(screen *ebiten.Image) {
+	c := screenCanvas(screen, g.win.w)
+	g.scene.draw(g, c)
+	if g.look.fps {
+		drawFPS(c, g.look)
 	}
-	g.ents = alive
+	g.drawFade(c)
+	drawToast(c, g.toast, g.toastErr, g.toastLeft)
+}
 
-	return nil
+func (g *Game) view() view {
+	return cameraView(g.world, g.win)
 }
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Fill(color.RGBA{245, 245, 245, 255})
+// drawWorld draws the arena through v, its entities, the player with its
+// dash trail and, if the settings show it, the HUD.
+func drawWorld(c canvas, v view, w *sim.World, trail *dashTrail, lk look) {
+	pal := lk.pal
+	c.fill(pal.Background)
+	wc := v.on(c)
+	x0, y0, x1, y1 := w.Bounds()
+	wc.rect(x0, y0, x1-x0, y1-y0, pal.Arena)
+	if scrolling(w) {
+		drawGridLines(c, v, w, pal)
+	}
 
-	for _, e := range g.ents {
-		if e.kind == KindSquare {
-			drawSquareAA(screen, e.x, e.y, e.size, e.col)
+	for _, e := range w.Entities() {
+		if e.Kind == sim.KindSquare {
+			wc.square(e.X, e.Y, e.Size, 0, pal.entity(e.Kind))
 		} else {
-			drawFilledCircle(screen, float32(e.x), float32(e.y), float32(e.size/2), e.col)
+			wc.circle(e.X, e.Y, e.Size/2, pal.entity(e.Kind))
 		}
 	}
 
-	if g.invincibleLeft > 0 {
-		drawRing(screen, float32(g.player.x), float32(g.player.y), float32(g.player.size*0.80), 4, color.RGBA{40, 150, 165, 220})
-	} else if g.dashInvLeft > 0 {
-		drawRing(screen, float32(g.player.x), float32(g.player.y), float32(g.player.size*0.75), 3, color.RGBA{120, 120, 120, 200})
+	p := w.Player()
+	// Rings keep their on-screen thickness however far out the camera is.
+	if w.InvincibleLeft() > 0 {
+		wc.ring(p.X, p.Y, p.Size*0.80, 4/v.scale, pal.Invincible)
+	} else if w.DashInvLeft() > 0 {
+		wc.ring(p.X, p.Y, p.Size*0.75, 3/v.scale, pal.Dash)
 	}
 
-	drawRotatedSquare(screen, g.player.x, g.player.y, g.player.size, g.angle, g.player.col)
-
-	invLeft := g.invincibleLeft
-	if g.dashInvLeft > invLeft {
-		invLeft = g.dashInvLeft
-	}
-	drawHUD(screen, 12, 12, g.score, invLeft)
-	drawTopPopup(screen, g.popupText, g.popupLeft, invinciblePopupDur)
-	if !g.gameOver {
-		if g.paused {
-			drawPauseOverlay(screen)
-		}
-	}
+	trail.draw(wc, pal.Player)
+	wc.square(p.X, p.Y, p.Size, w.Angle(), pal.Player)
 
-	if g.gameOver {
-		text.Draw(screen, "GAME OVER\nPress R to restart", hudFace, ScreenWidth/2-90, ScreenHeight/2, color.RGBA{20, 20, 20, 255})
+	if lk.hud {
+		invLeft := max(w.InvincibleLeft(), w.DashInvLeft())
+		drawHUD(c, lk, 12, 12, w.Score(), invLeft, w.DashCooldownLeft(), w.Tuning().Dash.Cooldown)
 	}
 }
 
 func (g *Game) Layout(outsideW, outsideH int) (int, int) {
-	return ScreenWidth, ScreenHeight
+	return g.win.layout(outsideW, outsideH)
 }
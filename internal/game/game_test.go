@@ -0,0 +1,166 @@
+package game
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/jdefrancesco/squares/internal/input"
+	"github.com/jdefrancesco/squares/internal/settings"
+	"github.com/jdefrancesco/squares/internal/sim"
+	"github.com/jdefrancesco/squares/internal/ui"
+)
+
+func TestAdvanceRunsFixedTicks(t *testing.T) {
+	g := New()
+	in := sim.Input{Target: true, X: ScreenWidth / 2, Y: ScreenHeight / 2}
+
+	if n := g.advance(0.1, in); n != 6 {
+		t.Fatalf("expected 6 ticks for 0.1s, got %d", n)
+	}
+	if math.Abs(g.world.Elapsed()-6*sim.TickDT) > 1e-9 {
+		t.Fatalf("expected elapsed=%v, got %v", 6*sim.TickDT, g.world.Elapsed())
+	}
+
+	// Less than a tick of time runs nothing but is carried over.
+	g = New()
+	if n := g.advance(sim.TickDT/2, in); n != 0 {
+		t.Fatalf("expected 0 ticks for half a tick, got %d", n)
+	}
+	if n := g.advance(sim.TickDT/2, in); n != 1 {
+		t.Fatalf("expected carried-over time to run 1 tick, got %d", n)
+	}
+}
+
+func TestAdvanceDropsLongStalls(t *testing.T) {
+	g := New()
+	in := sim.Input{Target: true, X: ScreenWidth / 2, Y: ScreenHeight / 2}
+
+	if n := g.advance(10, in); n != maxTicksPerUpdate {
+		t.Fatalf("expected stall to be capped at %d ticks, got %d", maxTicksPerUpdate, n)
+	}
+}
+
+func TestAdvanceKeepsDashUntilTickRuns(t *testing.T) {
+	g := New()
+	in := sim.Input{Target: true, X: ScreenWidth / 2, Y: ScreenHeight / 2}
+
+	g.advance(0, sim.Input{Target: true, X: in.X, Y: in.Y, Dash: true})
+	if g.world.DashInvLeft() != 0 {
+		t.Fatalf("expected no dash before any tick ran")
+	}
+	g.advance(sim.TickDT, in)
+	if g.world.DashInvLeft() <= 0 {
+		t.Fatalf("expected queued dash to apply on the next tick")
+	}
+}
+
+func TestTuningWatcherNoticesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuning.json")
+	os.WriteFile(path, []byte(`{}`), 0o644)
+
+	now := time.Now()
+	w := newTuningWatcher(path, now)
+
+	now = now.Add(tuningPollInterval)
+	if w.changed(now) {
+		t.Fatalf("expected an untouched file to be unchanged")
+	}
+
+	os.WriteFile(path, []byte(`{"growth_scale": 0.1}`), 0o644)
+	os.Chtimes(path, now, now.Add(time.Second))
+	if w.changed(now.Add(tuningPollInterval / 2)) {
+		t.Fatalf("expected no check before the poll interval elapsed")
+	}
+	now = now.Add(tuningPollInterval)
+	if !w.changed(now) {
+		t.Fatalf("expected the edit to be noticed")
+	}
+	now = now.Add(tuningPollInterval)
+	if w.changed(now) {
+		t.Fatalf("expected the edit to be reported only once")
+	}
+}
+
+func TestScenesFadeBetweenStates(t *testing.T) {
+	g := New()
+	if _, ok := g.scene.(*titleScene); !ok {
+		t.Fatalf("expected to start on the title, got %T", g.scene)
+	}
+
+	// Play is selected first.
+	g.updateScene(ui.Input{Frame: input.Frame{Pressed: input.Confirm}}, 0)
+	if _, ok := g.next.(*playScene); !ok {
+		t.Fatalf("expected a fade to play, got %T", g.next)
+	}
+	g.updateScene(ui.Input{Frame: input.Frame{Pressed: input.Confirm}}, fadeDuration/2)
+	if _, ok := g.scene.(*titleScene); !ok {
+		t.Fatalf("expected the title to stay up until the fade out ends")
+	}
+	g.updateScene(ui.Input{}, fadeDuration/2)
+	if _, ok := g.scene.(*playScene); !ok {
+		t.Fatalf("expected play after the fade, got %T", g.scene)
+	}
+
+	g.updateScene(ui.Input{Frame: input.Frame{Pressed: input.Pause}}, 0)
+	g.updateScene(ui.Input{}, fadeDuration)
+	if _, ok := g.scene.(*pauseScene); !ok {
+		t.Fatalf("expected pausing to open the pause menu, got %T", g.scene)
+	}
+	elapsed := g.world.Elapsed()
+	g.updateScene(ui.Input{}, 1)
+	if g.world.Elapsed() != elapsed {
+		t.Fatalf("expected the run to hold still while paused")
+	}
+
+	g.updateScene(ui.Input{Frame: input.Frame{Pressed: input.Back}}, 0)
+	g.updateScene(ui.Input{}, fadeDuration)
+	if _, ok := g.scene.(*playScene); !ok {
+		t.Fatalf("expected Back to resume, got %T", g.scene)
+	}
+	if g.world.Elapsed() != elapsed {
+		t.Fatalf("expected resuming to carry on the same run")
+	}
+}
+
+func TestControlSchemeGatesSteering(t *testing.T) {
+	ctl := &controls{scheme: settings.SchemeKeys, mouseSens: 1}
+	mouse := steering{&input.Scripted{States: []input.State{
+		{Target: true, X: 5, Y: 5, Held: input.Click},
+	}}, ctl, settings.SchemeMouse}
+
+	s := mouse.Poll()
+	if s.Target || s.Held != input.Click {
+		t.Fatalf("expected keys-only to drop the mouse's steering but keep its buttons, got %+v", s)
+	}
+	ctl.scheme = settings.SchemeAuto
+	if s := mouse.Poll(); !s.Target {
+		t.Fatalf("expected auto to steer with the mouse")
+	}
+}
+
+func TestControlsFollowBindings(t *testing.T) {
+	s := settings.Default()
+	s.Bindings = s.Bindings.Without(input.Dash, input.MouseButton("Left")).
+		With(input.Dash, input.MouseButton("Right")).
+		With(input.Dash, input.Key("NoSuchKey"))
+	ctl := newControls(s)
+
+	if !slices.Contains(ctl.buttons, buttonBinding{ebiten.MouseButtonRight, input.Dash}) ||
+		slices.Contains(ctl.buttons, buttonBinding{ebiten.MouseButtonLeft, input.Dash}) {
+		t.Fatalf("expected dash moved to the right button, got %v", ctl.buttons)
+	}
+	if !slices.Contains(ctl.buttons, buttonBinding{ebiten.MouseButtonLeft, input.Click}) {
+		t.Fatalf("expected the left button to still click, got %v", ctl.buttons)
+	}
+	for _, kb := range ctl.keys {
+		if kb.action == input.Dash && kb.key != ebiten.KeySpace {
+			t.Fatalf("expected only Space to dash, got %v", kb.key)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image/color"
 	"math"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
@@ -19,10 +20,15 @@ type hudLine struct {
 	value string
 }
 
-func drawHUD(screen *ebiten.Image, x, y int, score int, invLeft float64) {
+func drawHUD(c canvas, lk look, x, y int, score int, invLeft, dashLeft, dashCooldown float64) {
+	dash := lk.lang.tr("ready")
+	if dashLeft > 0 {
+		dash = fmt.Sprintf("%.1fs", dashLeft)
+	}
 	lines := []hudLine{
-		{label: "Score:", value: fmt.Sprintf("%d", score)},
-		{label: "Invincible:", value: fmt.Sprintf("%.1fs", math.Max(0, invLeft))},
+		{label: lk.lang.tr("Score:"), value: fmt.Sprintf("%d", score)},
+		{label: lk.lang.tr("Invincible:"), value: fmt.Sprintf("%.1fs", math.Max(0, invLeft))},
+		{label: lk.lang.tr("Dash:"), value: dash},
 	}
 
 	lineHeight := hudFace.Metrics().Height.Ceil()
@@ -30,8 +36,8 @@ func drawHUD(screen *ebiten.Image, x, y int, score int, invLeft float64) {
 
 	pad := 10
 
-	labelCol := color.RGBA{5, 5, 5, 255}
-	valueCol := color.RGBA{35, 35, 35, 255}
+	labelCol := lk.pal.Ink
+	valueCol := lk.pal.InkSoft
 	shadowCol := color.RGBA{0, 0, 0, 70}
 
 	baseY := y + pad + ascent
@@ -44,18 +50,33 @@ func drawHUD(screen *ebiten.Image, x, y int, score int, invLeft float64) {
 		x0 := x + pad
 
 		// Label: simulate bold by drawing twice with a 1px offset.
-		text.Draw(screen, line.label, hudFace, x0+1, ly+1, shadowCol)
-		text.Draw(screen, line.label, hudFace, x0, ly, labelCol)
-		text.Draw(screen, line.label, hudFace, x0+1, ly, labelCol)
+		c.text(line.label, x0+1, ly+1, shadowCol)
+		c.text(line.label, x0, ly, labelCol)
+		c.text(line.label, x0+1, ly, labelCol)
 
 		if line.value != "" {
 			labelW := text.BoundString(hudFace, line.label+" ").Dx()
-			text.Draw(screen, line.value, hudFace, x0+labelW, ly, valueCol)
+			c.text(line.value, x0+labelW, ly, valueCol)
 		}
 	}
+
+	// Under the dash line, a bar fills up as the cooldown runs out.
+	barY := float64(baseY + (len(lines)-1)*lineHeight + 4)
+	barX := float64(x + pad)
+	const barW, barH = 80, 3
+	filled := 1.0
+	if dashCooldown > 0 {
+		filled = 1 - math.Min(1, dashLeft/dashCooldown)
+	}
+	barCol := lk.pal.Player
+	if filled < 1 {
+		barCol = lk.pal.Dash
+	}
+	c.rect(barX, barY, barW, barH, lk.pal.Shade)
+	c.rect(barX, barY, barW*filled, barH, barCol)
 }
 
-func drawTopPopup(screen *ebiten.Image, msg string, left, total float64) {
+func drawTopPopup(c canvas, pal palette, msg string, left, total float64) {
 	if msg == "" || left <= 0 || total <= 0 {
 		return
 	}
@@ -66,20 +87,53 @@ func drawTopPopup(screen *ebiten.Image, msg string, left, total float64) {
 	}
 
 	b := text.BoundString(hudFace, msg)
-	x := (ScreenWidth - b.Dx()) / 2
+	w, _ := c.size()
+	x := (int(w) - b.Dx()) / 2
 	y := 24
 
-	labelCol := color.RGBA{20, 20, 20, uint8(235 * alpha)}
+	labelCol := fade(pal.Ink, 235.0/255*alpha)
 	shadowCol := color.RGBA{0, 0, 0, uint8(90 * alpha)}
-	text.Draw(screen, msg, hudFace, x+1, y+1, shadowCol)
-	text.Draw(screen, msg, hudFace, x, y, labelCol)
+	c.text(msg, x+1, y+1, shadowCol)
+	c.text(msg, x, y, labelCol)
 }
 
-func drawPauseOverlay(screen *ebiten.Image) {
-	overlay := ebiten.NewImage(ScreenWidth, ScreenHeight)
-	overlay.Fill(color.RGBA{0, 0, 0, 70})
-	screen.DrawImage(overlay, nil)
+// drawFPS shows the frame rate in the bottom-right corner.
+func drawFPS(c canvas, lk look) {
+	msg := fmt.Sprintf("%.0f FPS", ebiten.ActualFPS())
+	w, h := c.size()
+	c.text(msg, int(w)-12-text.BoundString(hudFace, msg).Dx(), int(h)-12, lk.pal.InkSoft)
+}
+
+// drawToast shows a boxed, possibly multi-line message in the bottom-left
+// corner, fading out over its last half second.
+func drawToast(c canvas, msg string, isErr bool, left float64) {
+	if msg == "" || left <= 0 {
+		return
+	}
+	alpha := math.Min(1, left/0.5)
 
-	text.Draw(screen, "PAUSED", hudFace, ScreenWidth/2-21, ScreenHeight/2-8, color.RGBA{255, 255, 255, 230})
-	text.Draw(screen, "Press P or Esc to resume", hudFace, ScreenWidth/2-77, ScreenHeight/2+12, color.RGBA{255, 255, 255, 220})
+	lines := strings.Split(msg, "\n")
+	lineHeight := hudFace.Metrics().Height.Ceil()
+	ascent := hudFace.Metrics().Ascent.Ceil()
+	pad := 8
+
+	w := 0
+	for _, l := range lines {
+		w = max(w, text.BoundString(hudFace, l).Dx())
+	}
+	h := len(lines) * lineHeight
+	_, screenH := c.size()
+	x := 12
+	y := int(screenH) - 12 - h - 2*pad
+
+	bg := color.RGBA{30, 30, 30, uint8(200 * alpha)}
+	if isErr {
+		bg = color.RGBA{150, 30, 30, uint8(220 * alpha)}
+	}
+	c.rect(float64(x), float64(y), float64(w+2*pad), float64(h+2*pad), bg)
+
+	textCol := color.RGBA{255, 255, 255, uint8(240 * alpha)}
+	for i, l := range lines {
+		c.text(l, x+pad, y+pad+ascent+i*lineHeight, textCol)
+	}
 }
@@ -0,0 +1,273 @@
+package game
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/jdefrancesco/squares/internal/input"
+	"github.com/jdefrancesco/squares/internal/settings"
+	"github.com/jdefrancesco/squares/internal/ui"
+)
+
+// defaultInput is every device the game listens to out of the box. Touch
+// comes first so a finger wins over the cursor some systems move with it.
+// Positions are reported in logical window pixels.
+func defaultInput(win *window, ctl *controls, pad *input.Gamepad, touch *input.TouchSource) input.Source {
+	return input.Multi{
+		steering{touch, ctl, settings.SchemeMouse},
+		steering{&mouseSource{win: win, ctl: ctl}, ctl, settings.SchemeMouse},
+		steering{keyboardSource{ctl}, ctl, settings.SchemeKeys},
+		steering{pad, ctl, settings.SchemeKeys},
+	}
+}
+
+// controls is what the settings change about reading the devices.
+type controls struct {
+	scheme    settings.Scheme
+	mouseSens float64
+	keys      []keyBinding
+	buttons   []buttonBinding
+}
+
+type keyBinding struct {
+	key    ebiten.Key
+	action input.Action
+}
+
+type buttonBinding struct {
+	button ebiten.MouseButton
+	action input.Action
+}
+
+// newControls reads the devices as s says. Keys and buttons this build
+// does not know are left unbound.
+func newControls(s settings.Settings) controls {
+	ctl := controls{scheme: s.Controls, mouseSens: s.MouseSensitivity}
+	for _, a := range input.Actions() {
+		for _, c := range s.Bindings.On(a, input.Keyboard) {
+			var k ebiten.Key
+			if err := k.UnmarshalText([]byte(c.Name)); err == nil {
+				ctl.keys = append(ctl.keys, keyBinding{k, a})
+			}
+		}
+		for _, c := range s.Bindings.On(a, input.Mouse) {
+			for _, mb := range mouseButtons {
+				if mb.name == c.Name {
+					ctl.buttons = append(ctl.buttons, buttonBinding{mb.button, a})
+				}
+			}
+		}
+	}
+	return ctl
+}
+
+// steering passes on src's buttons always but its steering only if the
+// control scheme steers with scheme.
+type steering struct {
+	src    input.Source
+	ctl    *controls
+	scheme settings.Scheme
+}
+
+func (s steering) Poll() input.State {
+	st := s.src.Poll()
+	if sc := s.ctl.scheme; sc != settings.SchemeAuto && sc != s.scheme {
+		st.Target = false
+		st.MoveX, st.MoveY = 0, 0
+	}
+	return st
+}
+
+// mouseSource reports the cursor as a target whenever it moves, so a still
+// mouse does not fight the keyboard or a stick, and presses the actions
+// bound to its buttons.
+//
+// The target moves by the mouse sensitivity times as far as the cursor
+// does, so away from 1 it drifts from the cursor, which still points at
+// menus.
+type mouseSource struct {
+	win    *window
+	ctl    *controls
+	x, y   float64
+	tx, ty float64
+	seen   bool
+}
+
+func (m *mouseSource) Poll() input.State {
+	var s input.State
+
+	// Touchscreens often emulate the mouse; the touch source has it.
+	if len(ebiten.AppendTouchIDs(nil)) > 0 {
+		return s
+	}
+
+	cx, cy := ebiten.CursorPosition()
+	x := max(0, min(float64(cx)/m.win.dpi, m.win.w-1))
+	y := max(0, min(float64(cy)/m.win.dpi, m.win.h-1))
+	if !m.seen || m.ctl.mouseSens == 1 {
+		m.tx, m.ty = x, y
+	} else {
+		m.tx = max(0, min(m.tx+(x-m.x)*m.ctl.mouseSens, m.win.w-1))
+		m.ty = max(0, min(m.ty+(y-m.y)*m.ctl.mouseSens, m.win.h-1))
+	}
+	if !m.seen || x != m.x || y != m.y {
+		s.Target = true
+		s.X, s.Y = m.tx, m.ty
+	}
+	m.x, m.y, m.seen = x, y, true
+	s.Pointer = true
+	s.PointerX, s.PointerY = x, y
+
+	for _, b := range m.ctl.buttons {
+		if ebiten.IsMouseButtonPressed(b.button) {
+			s.Held |= b.action
+		}
+	}
+	return s
+}
+
+// keyboardSource presses the actions bound to keys, and steers with the
+// keys bound to Up, Down, Left and Right.
+type keyboardSource struct {
+	ctl *controls
+}
+
+func (k keyboardSource) Poll() input.State {
+	var s input.State
+	for _, kb := range k.ctl.keys {
+		if ebiten.IsKeyPressed(kb.key) {
+			s.Held |= kb.action
+		}
+	}
+
+	if s.Held.Has(input.Left) {
+		s.MoveX--
+	}
+	if s.Held.Has(input.Right) {
+		s.MoveX++
+	}
+	if s.Held.Has(input.Up) {
+		s.MoveY--
+	}
+	if s.Held.Has(input.Down) {
+		s.MoveY++
+	}
+	return s
+}
+
+// mouseButtons names the mouse buttons for bindings.
+var mouseButtons = []struct {
+	name   string
+	button ebiten.MouseButton
+}{
+	{"Left", ebiten.MouseButtonLeft},
+	{"Right", ebiten.MouseButtonRight},
+	{"Middle", ebiten.MouseButtonMiddle},
+	{"Back", ebiten.MouseButton3},
+	{"Forward", ebiten.MouseButton4},
+}
+
+// justPressed is a key or button that went down this frame, for binding
+// to an action.
+func justPressed() (input.Control, bool) {
+	if keys := inpututil.AppendJustPressedKeys(nil); len(keys) > 0 {
+		return input.Key(keys[0].String()), true
+	}
+	for _, mb := range mouseButtons {
+		if inpututil.IsMouseButtonJustPressed(mb.button) {
+			return input.MouseButton(mb.name), true
+		}
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		for pb := input.PadSouth; pb <= input.PadRight; pb++ {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, padButtons[pb]) {
+				return input.PadControl(pb), true
+			}
+		}
+	}
+	return input.Control{}, false
+}
+
+// uiInput adds what the menus need beyond actions to f: typed text and
+// the mouse wheel.
+func uiInput(f input.Frame) ui.Input {
+	_, wheel := ebiten.Wheel()
+	return ui.Input{
+		Frame:  f,
+		Chars:  ebiten.AppendInputChars(nil),
+		Enter:  inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter),
+		Erase:  inpututil.IsKeyJustPressed(ebiten.KeyBackspace),
+		Scroll: wheel,
+	}
+}
+
+// ebitenPads is input.Pads for the gamepads Ebiten sees.
+type ebitenPads struct{}
+
+var padButtons = map[input.PadButton]ebiten.StandardGamepadButton{
+	input.PadSouth:         ebiten.StandardGamepadButtonRightBottom,
+	input.PadEast:          ebiten.StandardGamepadButtonRightRight,
+	input.PadWest:          ebiten.StandardGamepadButtonRightLeft,
+	input.PadNorth:         ebiten.StandardGamepadButtonRightTop,
+	input.PadLeftShoulder:  ebiten.StandardGamepadButtonFrontTopLeft,
+	input.PadRightShoulder: ebiten.StandardGamepadButtonFrontTopRight,
+	input.PadBack:          ebiten.StandardGamepadButtonCenterLeft,
+	input.PadStart:         ebiten.StandardGamepadButtonCenterRight,
+	input.PadUp:            ebiten.StandardGamepadButtonLeftTop,
+	input.PadDown:          ebiten.StandardGamepadButtonLeftBottom,
+	input.PadLeft:          ebiten.StandardGamepadButtonLeftLeft,
+	input.PadRight:         ebiten.StandardGamepadButtonLeftRight,
+}
+
+var padAxes = map[input.PadAxis]ebiten.StandardGamepadAxis{
+	input.PadLeftX:  ebiten.StandardGamepadAxisLeftStickHorizontal,
+	input.PadLeftY:  ebiten.StandardGamepadAxisLeftStickVertical,
+	input.PadRightX: ebiten.StandardGamepadAxisRightStickHorizontal,
+	input.PadRightY: ebiten.StandardGamepadAxisRightStickVertical,
+}
+
+func (ebitenPads) IDs() []int {
+	var ids []int
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			ids = append(ids, int(id))
+		}
+	}
+	return ids
+}
+
+func (ebitenPads) Axis(id int, a input.PadAxis) float64 {
+	return ebiten.StandardGamepadAxisValue(ebiten.GamepadID(id), padAxes[a])
+}
+
+func (ebitenPads) Pressed(id int, b input.PadButton) bool {
+	return ebiten.IsStandardGamepadButtonPressed(ebiten.GamepadID(id), padButtons[b])
+}
+
+func (ebitenPads) Vibrate(id int, strength float64, d time.Duration) {
+	ebiten.VibrateGamepad(ebiten.GamepadID(id), &ebiten.VibrateGamepadOptions{
+		Duration:        d,
+		StrongMagnitude: strength,
+		WeakMagnitude:   strength,
+	})
+}
+
+// ebitenTouches is input.TouchScreen for Ebiten's touch API, in logical
+// window pixels.
+type ebitenTouches struct {
+	win *window
+}
+
+func (t ebitenTouches) Touches() []input.Touch {
+	var ts []input.Touch
+	for _, id := range ebiten.AppendTouchIDs(nil) {
+		x, y := ebiten.TouchPosition(id)
+		ts = append(ts, input.Touch{ID: int(id), X: float64(x) / t.win.dpi, Y: float64(y) / t.win.dpi})
+	}
+	return ts
+}
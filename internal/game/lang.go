@@ -0,0 +1,118 @@
+package game
+
+import "fmt"
+
+// language is the game's text in one language, keyed by the English.
+// Text without a translation stays in English.
+type language struct {
+	tag  string
+	name string
+	text map[string]string
+}
+
+// languages are those the game speaks, English first. The HUD font only
+// has ASCII, so translations are written without accents.
+var languages = []language{
+	{tag: "en", name: "English"},
+	{tag: "es", name: "Castellano", text: map[string]string{
+		"Play":                             "Jugar",
+		"Settings":                         "Ajustes",
+		"Quit":                             "Salir",
+		"Best: %d":                         "Mejor: %d",
+		"PAUSED":                           "PAUSA",
+		"Press %s to resume":               "Pulsa %s para seguir",
+		"Resume":                           "Seguir",
+		"Restart":                          "Reiniciar",
+		"Quit to title":                    "Ir al inicio",
+		"Play again":                       "Jugar otra vez",
+		"Title":                            "Inicio",
+		"SETTINGS":                         "AJUSTES",
+		"Changes apply from the next run.": "Se aplica en la siguiente partida.",
+		"Back":                             "Volver",
+		"Game":                             "Juego",
+		"Video":                            "Video",
+		"Audio":                            "Sonido",
+		"Master volume %.0f%%":             "Volumen general %.0f%%",
+		"Music volume %.0f%%":              "Volumen de musica %.0f%%",
+		"Effects volume %.0f%%":            "Volumen de efectos %.0f%%",
+		"The game has no sound yet.":       "El juego aun no tiene sonido.",
+		"Controls":                         "Controles",
+		"Display":                          "Pantalla",
+		"Mode":                             "Modo",
+		"World":                            "Mundo",
+		"Language":                         "Idioma",
+		"Fullscreen":                       "Pantalla completa",
+		"Window size":                      "Ventana",
+		"Vsync":                            "Vsync",
+		"FPS cap":                          "Tope de FPS",
+		"No limit":                         "Sin tope",
+		"On":                               "Activado",
+		"Off":                              "Desactivado",
+		"Steer with":                       "Mover con",
+		"Anything":                         "Cualquiera",
+		"Mouse":                            "Mouse",
+		"Keys and gamepad":                 "Teclas y mando",
+		"Mouse speed %gx":                  "Velocidad del mouse %gx",
+		"Stick speed %gx":                  "Velocidad del stick %gx",
+		"Keys and buttons...":              "Teclas y botones...",
+		"KEYS AND BUTTONS":                 "TECLAS Y BOTONES",
+		"Dash":                             "Impulso",
+		"Pause":                            "Pausa",
+		"Up":                               "Arriba",
+		"Down":                             "Abajo",
+		"Left":                             "Izquierda",
+		"Right":                            "Derecha",
+		"Confirm":                          "Aceptar",
+		"Click":                            "Clic",
+		"Pad":                              "Mando",
+		"Reset all":                        "Restablecer todo",
+		"%s moved from %s.":                "%s quitada de %s.",
+		"Theme":                            "Tema",
+		"Light":                            "Claro",
+		"Dark":                             "Oscuro",
+		"Colorblind colors":                "Modo daltonismo",
+		"HUD":                              "HUD",
+		"Minimap":                          "Minimapa",
+		"FPS counter":                      "Contador de FPS",
+		"Score:":                           "Puntos:",
+		"Invincible:":                      "Invencible:",
+		"Dash:":                            "Impulso:",
+		"ready":                            "listo",
+		"INVINCIBLE":                       "INVENCIBLE",
+		"GAME OVER":                        "FIN DE LA PARTIDA",
+		"Press %s to play again":           "Pulsa %s para jugar otra vez",
+		"Score: %d":                        "Puntos: %d",
+		"Seed: %d":                         "Semilla: %d",
+		"New personal best!":               "Nuevo record personal!",
+
+		"Every action is back to its defaults.":                     "Todo vuelve a los valores por defecto.",
+		"Press a key or button for %s. Esc cancels, Delete clears.": "Pulsa una tecla o boton para %s. Esc cancela, Supr borra.",
+		"Cancelling. Press Esc again to bind it.":                   "Cancelando. Pulsa Esc otra vez para asignarla.",
+		"Clearing the slot. Press Delete again to bind it.":         "Borrando la casilla. Pulsa Supr otra vez para asignarla.",
+		"Pick a slot to bind it. ! marks a clash.":                  "Elige una casilla para asignarla. ! marca un choque.",
+		"%s is already %s. Press it again to move it here.":         "%s ya es %s. Pulsala otra vez para moverla aqui.",
+	}},
+}
+
+// languageFor is the language tagged tag, or English.
+func languageFor(tag string) language {
+	for _, l := range languages {
+		if l.tag == tag {
+			return l
+		}
+	}
+	return languages[0]
+}
+
+// tr is s in l.
+func (l language) tr(s string) string {
+	if t, ok := l.text[s]; ok {
+		return t
+	}
+	return s
+}
+
+// trf is format in l, filled in with args.
+func (l language) trf(format string, args ...any) string {
+	return fmt.Sprintf(l.tr(format), args...)
+}
@@ -0,0 +1,73 @@
+package game
+
+import (
+	"math"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+const (
+	// Grid lines on a scrolling arena are this many world units apart, so
+	// movement shows against the background.
+	gridSpacing = 80
+
+	// The minimap's longer side, in logical pixels.
+	minimapSize = 120
+)
+
+// scrolling reports whether w's arena is bigger than its camera's view.
+func scrolling(w *sim.World) bool {
+	x0, y0, x1, y1 := w.Bounds()
+	vw, vh := w.ViewSize()
+	return x1-x0 > vw || y1-y0 > vh
+}
+
+// drawGridLines draws the lines of w's arena that fall within the window
+// c shows through v.
+func drawGridLines(c canvas, v view, w *sim.World, pal palette) {
+	wc := v.on(c)
+	cw, ch := c.size()
+	bx0, by0, bx1, by1 := w.Bounds()
+	x0, y0 := v.toWorld(0, 0)
+	x1, y1 := v.toWorld(cw, ch)
+	x0, y0 = math.Max(bx0, x0), math.Max(by0, y0)
+	x1, y1 = math.Min(bx1, x1), math.Min(by1, y1)
+
+	line := 1 / v.scale
+	col := pal.Grid
+	for x := math.Ceil(x0/gridSpacing) * gridSpacing; x <= x1; x += gridSpacing {
+		wc.rect(x, y0, line, y1-y0, col)
+	}
+	for y := math.Ceil(y0/gridSpacing) * gridSpacing; y <= y1; y += gridSpacing {
+		wc.rect(x0, y, x1-x0, line, col)
+	}
+}
+
+// drawMinimap draws the whole arena in the top-right corner, top logical
+// pixels down: the camera's view as an outline, entities as dots and the
+// player as a bigger one.
+func drawMinimap(c canvas, w *sim.World, pal palette, top float64) {
+	bx, by, bx1, by1 := w.Bounds()
+	aw, ah := bx1-bx, by1-by
+	s := minimapSize / math.Max(aw, ah)
+	cw, _ := c.size()
+	// World point (x, y) is at (x*s, y*s) on mc.
+	mc := c.within(cw-12-(bx+aw)*s, top-by*s, 1)
+
+	mc.rect(bx*s, by*s, aw*s, ah*s, pal.Shade)
+
+	vw, vh := w.ViewSize()
+	cx, cy := w.Camera()
+	vx, vy := (cx-vw/2)*s, (cy-vh/2)*s
+	outline := fade(pal.InkSoft, 200.0/255)
+	mc.rect(vx, vy, vw*s, 1, outline)
+	mc.rect(vx, vy+vh*s-1, vw*s, 1, outline)
+	mc.rect(vx, vy, 1, vh*s, outline)
+	mc.rect(vx+vw*s-1, vy, 1, vh*s, outline)
+
+	for _, e := range w.Entities() {
+		mc.rect(e.X*s-1, e.Y*s-1, 2, 2, pal.entity(e.Kind))
+	}
+	p := w.Player()
+	mc.rect(p.X*s-2, p.Y*s-2, 4, 4, pal.Player)
+}
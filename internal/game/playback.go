@@ -0,0 +1,152 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/jdefrancesco/squares/internal/replay"
+	"github.com/jdefrancesco/squares/internal/settings"
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+var playbackSpeeds = []float64{0.25, 0.5, 1, 2, 4}
+
+const (
+	defaultSpeedIdx = 2
+	seekTicks       = 5 * sim.TickRate
+)
+
+// Playback is an ebiten.Game that re-runs a recorded replay. Seeking
+// backwards re-simulates from the start, which is cheap next to drawing.
+type Playback struct {
+	rep   *replay.Replay
+	world *sim.World
+	tick  int
+	trail dashTrail
+	win   window
+	look  look
+
+	paused   bool
+	speedIdx int
+
+	accum float64
+	last  time.Time
+}
+
+func NewPlayback(r *replay.Replay) *Playback {
+	p := &Playback{rep: r, speedIdx: defaultSpeedIdx, last: time.Now(), win: newWindow()}
+	p.world = sim.New(r.Options())
+	p.look = lookFor(settings.Default())
+	return p
+}
+
+// UseSettings draws playback the way s asks.
+func (p *Playback) UseSettings(s settings.Settings) {
+	p.look = lookFor(s)
+}
+
+func (p *Playback) Update() error {
+	now := time.Now()
+	frame := now.Sub(p.last).Seconds()
+	p.last = now
+
+	toggleFullscreen()
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyQ), inpututil.IsKeyJustPressed(ebiten.KeyEscape), ebiten.IsWindowBeingClosed():
+		return ebiten.Termination
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		p.paused = !p.paused
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		p.seek(p.tick + seekTicks)
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		p.seek(p.tick - seekTicks)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		p.seek(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		if p.paused {
+			p.seek(p.tick + 1)
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		p.speedIdx = min(p.speedIdx+1, len(playbackSpeeds)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		p.speedIdx = max(p.speedIdx-1, 0)
+	}
+
+	if p.paused {
+		return nil
+	}
+
+	p.accum += frame * playbackSpeeds[p.speedIdx]
+	if limit := maxTicksPerUpdate * playbackSpeeds[p.speedIdx] * sim.TickDT; p.accum > limit {
+		p.accum = limit
+	}
+	for p.accum >= sim.TickDT && !p.done() {
+		p.accum -= sim.TickDT
+		p.stepOne()
+	}
+	return nil
+}
+
+func (p *Playback) done() bool {
+	return p.tick >= len(p.rep.Inputs) || p.world.Over()
+}
+
+func (p *Playback) stepOne() {
+	p.rep.Step(p.world, p.tick)
+	p.trail.update(p.world)
+	p.tick++
+}
+
+// seek moves playback to the given tick, clamped to the recording.
+func (p *Playback) seek(tick int) {
+	tick = max(0, min(tick, len(p.rep.Inputs)))
+	if tick < p.tick {
+		p.world = sim.New(p.rep.Options())
+		p.tick = 0
+		p.trail.reset()
+	}
+	for p.tick < tick && !p.done() {
+		p.stepOne()
+	}
+	p.accum = 0
+}
+
+func (p *Playback) Draw(screen *ebiten.Image) {
+	c := screenCanvas(screen, p.win.w)
+	drawWorld(c, cameraView(p.world, p.win), p.world, &p.trail, p.look)
+	if scrolling(p.world) && p.look.minimap {
+		drawMinimap(c, p.world, p.look.pal, 12)
+	}
+
+	status := "PLAYING"
+	switch {
+	case p.world.Over():
+		status = "ENDED: " + p.world.Result().Cause.String()
+	case p.paused:
+		status = "PAUSED"
+	}
+	line := fmt.Sprintf("REPLAY  %s / %s  %.2gx  %s",
+		formatTicks(p.tick), formatTicks(len(p.rep.Inputs)), playbackSpeeds[p.speedIdx], status)
+	help := "Space pause  Left/Right seek  Up/Down speed  . step  Home restart  Q quit"
+
+	h := int(p.win.h)
+	c.text(line, 12, h-30, p.look.pal.Ink)
+	c.text(help, 12, h-12, p.look.pal.InkSoft)
+	if p.look.fps {
+		drawFPS(c, p.look)
+	}
+}
+
+func (p *Playback) Layout(outsideW, outsideH int) (int, int) {
+	return p.win.layout(outsideW, outsideH)
+}
+
+// formatTicks renders a tick count as m:ss.s.
+func formatTicks(ticks int) string {
+	secs := float64(ticks) / sim.TickRate
+	m := int(secs) / 60
+	return fmt.Sprintf("%d:%04.1f", m, secs-float64(m*60))
+}
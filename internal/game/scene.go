@@ -0,0 +1,306 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/jdefrancesco/squares/internal/input"
+	"github.com/jdefrancesco/squares/internal/scores"
+	"github.com/jdefrancesco/squares/internal/sim"
+	"github.com/jdefrancesco/squares/internal/ui"
+)
+
+// scene is one screen of the game: the title, a run in play, a menu. The
+// Game runs one scene at a time and fades from one to the next.
+type scene interface {
+	// enter is called as the scene takes over, exit as it hands over.
+	enter(g *Game)
+	exit(g *Game)
+	// update handles one frame: frame seconds of wall-clock time and the
+	// input read in it, with positions in logical window pixels. Menus
+	// run their widgets here, on g.ui, and draw them in draw.
+	update(g *Game, in ui.Input, frame float64) error
+	draw(g *Game, c canvas)
+}
+
+// goTo fades out of the current scene and into s. Input is ignored until
+// the fade out is over.
+func (g *Game) goTo(s scene) {
+	if g.next != nil {
+		return
+	}
+	g.next = s
+	g.fade = 0
+}
+
+// setScene switches to s at once.
+func (g *Game) setScene(s scene) {
+	if g.scene != nil {
+		g.scene.exit(g)
+	}
+	g.scene = s
+	g.next = nil
+	g.fade = 0
+	g.ui.Reset()
+	s.enter(g)
+}
+
+// updateScene runs the fade, if any, and the current scene.
+func (g *Game) updateScene(in ui.Input, frame float64) error {
+	if g.next != nil {
+		g.fade += frame
+		if g.fade >= fadeDuration {
+			g.setScene(g.next)
+			g.fade = fadeDuration
+		}
+		return nil
+	}
+	g.fade = max(0, g.fade-frame)
+	return g.scene.update(g, in, frame)
+}
+
+// drawFade darkens the screen by how far through a fade the game is.
+func (g *Game) drawFade(c canvas) {
+	if g.fade <= 0 {
+		return
+	}
+	w, h := c.size()
+	a := min(1, g.fade/fadeDuration)
+	c.rect(0, 0, w, h, color.RGBA{0, 0, 0, uint8(255 * a)})
+}
+
+// Menus are columns of menuWidth wide rows, menuGap apart.
+const (
+	menuWidth = 200
+	menuGap   = 4
+)
+
+// screen is the whole window, for laying out menus.
+func (g *Game) screen() ui.Rect {
+	return ui.Rect{W: g.win.w, H: g.win.h}
+}
+
+// titleScene is the first thing shown: the arena waiting for a run.
+type titleScene struct{}
+
+func newTitleScene() *titleScene {
+	return &titleScene{}
+}
+
+func (s *titleScene) enter(g *Game) {}
+func (s *titleScene) exit(g *Game)  {}
+
+func (s *titleScene) update(g *Game, in ui.Input, frame float64) error {
+	u := g.ui
+	u.Begin(in, g.screen())
+	defer u.End()
+
+	u.Panel(u.Screen())
+	l := g.look.lang
+	row := u.RowHeight()
+	st := ui.VStack(ui.Centered(u.Screen(), menuWidth, 76+ui.StackHeight(4, row, menuGap)), menuGap)
+	u.Heading(st.Row(60), "SQUARES", 4)
+	if g.scores != nil && len(g.scores.Entries()) > 0 {
+		u.Hint(st.Row(row), l.trf("Best: %d", g.scores.Entries()[0].Score))
+	} else {
+		st.Row(row)
+	}
+	st.Space(12)
+
+	if u.Button("play", st.Row(row), l.tr("Play")) {
+		g.goTo(newPlayScene(true))
+	}
+	if u.Button("settings", st.Row(row), l.tr("Settings")) {
+		g.goTo(newSettingsScene(s))
+	}
+	if u.Button("quit", st.Row(row), l.tr("Quit")) {
+		return ebiten.Termination
+	}
+	return nil
+}
+
+func (s *titleScene) draw(g *Game, c canvas) {
+	drawWorld(c, g.view(), g.world, &g.trail, g.look)
+	g.ui.Draw(uiPainter{c})
+}
+
+// playScene is a run in progress.
+type playScene struct {
+	// fresh starts a new run on entering rather than carrying on.
+	fresh bool
+}
+
+func newPlayScene(fresh bool) *playScene {
+	return &playScene{fresh: fresh}
+}
+
+func (s *playScene) enter(g *Game) {
+	if s.fresh {
+		g.reset()
+	}
+}
+
+func (s *playScene) exit(g *Game) {}
+
+func (s *playScene) update(g *Game, in ui.Input, frame float64) error {
+	f := in.Frame
+	if f.Pressed.Has(input.Pause) {
+		g.goTo(newPauseScene())
+		return nil
+	}
+	if f.Target {
+		f.X, f.Y = g.view().toWorld(f.X, f.Y)
+	}
+	g.advance(frame, sim.Input{
+		Target: f.Target,
+		X:      f.X,
+		Y:      f.Y,
+		MoveX:  f.MoveX,
+		MoveY:  f.MoveY,
+		Dash:   f.Pressed.Has(input.Dash),
+	})
+	if g.world.Over() {
+		g.goTo(newGameOverScene())
+	}
+	return nil
+}
+
+func (s *playScene) draw(g *Game, c canvas) {
+	g.drawRun(c)
+	if g.touch.Used() {
+		drawTouchButtons(c, g.look.pal, g.touch.Buttons())
+	}
+}
+
+// drawRun draws the run in progress, without anything over it.
+func (g *Game) drawRun(c canvas) {
+	drawWorld(c, g.view(), g.world, &g.trail, g.look)
+	if scrolling(g.world) && g.look.minimap {
+		// Keep clear of the pause button.
+		top := 12.0
+		if g.touch.Used() {
+			top = 64
+		}
+		drawMinimap(c, g.world, g.look.pal, top)
+	}
+	drawTopPopup(c, g.look.pal, g.look.lang.tr(g.popupText), g.popupLeft, invinciblePopupDur)
+}
+
+// pauseScene holds the run while the player picks what to do next.
+type pauseScene struct{}
+
+func newPauseScene() *pauseScene {
+	return &pauseScene{}
+}
+
+func (s *pauseScene) enter(g *Game) {}
+func (s *pauseScene) exit(g *Game)  {}
+
+func (s *pauseScene) update(g *Game, in ui.Input, frame float64) error {
+	u := g.ui
+	u.Begin(in, g.screen())
+	defer u.End()
+
+	u.Panel(u.Screen())
+	l := g.look.lang
+	row := u.RowHeight()
+	st := ui.VStack(ui.Centered(u.Screen(), menuWidth, 2*row+8+ui.StackHeight(5, row, menuGap)), menuGap)
+	u.Heading(st.Row(2*row), l.tr("PAUSED"), 2)
+	if k := g.keysFor(input.Pause); k != "" {
+		u.Hint(st.Row(row), l.trf("Press %s to resume", k))
+	} else {
+		st.Row(row)
+	}
+	st.Space(8)
+
+	if u.Button("resume", st.Row(row), l.tr("Resume")) {
+		g.goTo(newPlayScene(false))
+	}
+	if u.Button("restart", st.Row(row), l.tr("Restart")) {
+		g.goTo(newPlayScene(true))
+	}
+	if u.Button("settings", st.Row(row), l.tr("Settings")) {
+		g.goTo(newSettingsScene(s))
+	}
+	if u.Button("title", st.Row(row), l.tr("Quit to title")) {
+		g.abandon()
+		g.goTo(newTitleScene())
+	}
+	if u.Pressed(input.Pause) || u.Pressed(input.Back) {
+		g.goTo(newPlayScene(false))
+	}
+	return nil
+}
+
+func (s *pauseScene) draw(g *Game, c canvas) {
+	g.drawRun(c)
+	g.ui.Draw(uiPainter{c})
+}
+
+// gameOverScene shows how the run went and the high scores.
+type gameOverScene struct {
+	// How long the scene has been up, for restarting bot runs.
+	shown float64
+	// Where the menu starts; the scores go above it.
+	menuTop float64
+}
+
+func newGameOverScene() *gameOverScene {
+	return &gameOverScene{}
+}
+
+func (s *gameOverScene) enter(g *Game) {}
+func (s *gameOverScene) exit(g *Game)  {}
+
+func (s *gameOverScene) update(g *Game, in ui.Input, frame float64) error {
+	s.shown += frame
+	if s.shown < gameOverGrace {
+		// Whatever was being mashed at the moment of death is not a
+		// choice.
+		in = ui.Input{}
+	}
+	if in.Pressed.Has(input.Restart) || (g.policy != nil && s.shown >= botRestartDelay) {
+		g.goTo(newPlayScene(true))
+		return nil
+	}
+
+	u := g.ui
+	u.Begin(in, g.screen())
+	defer u.End()
+
+	row := u.RowHeight()
+	h := ui.StackHeight(2, row, menuGap)
+	s.menuTop = u.Screen().H - 12 - h
+	st := ui.VStack(ui.Rect{X: (u.Screen().W - menuWidth) / 2, Y: s.menuTop, W: menuWidth, H: h}, menuGap)
+	l := g.look.lang
+	if u.Button("again", st.Row(row), l.tr("Play again")) {
+		g.goTo(newPlayScene(true))
+	}
+	if u.Button("title", st.Row(row), l.tr("Title")) {
+		g.goTo(newTitleScene())
+	}
+	return nil
+}
+
+func (s *gameOverScene) draw(g *Game, c canvas) {
+	g.drawRun(c)
+
+	l := g.look.lang
+	again := ""
+	if k := g.keysFor(input.Restart); k != "" {
+		again = l.trf("Press %s to play again", k)
+	}
+	msg := fmt.Sprintf("%s\n%s\n\n%s\n%s", l.tr("GAME OVER"), again,
+		l.trf("Score: %d", g.world.Score()), l.trf("Seed: %d", g.seed))
+	if g.newBest {
+		msg += "\n" + l.tr("New personal best!")
+	}
+	var table []scores.Entry
+	if g.scores != nil {
+		table = g.scores.Entries()
+	}
+	drawGameOver(c, g.look.pal, msg, table, g.rank, s.menuTop)
+	g.ui.Draw(uiPainter{c})
+}
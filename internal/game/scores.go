@@ -0,0 +1,70 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"github.com/jdefrancesco/squares/internal/scores"
+)
+
+// drawGameOver shows msg above the high-score table, centered above
+// bottom, with the entry at index rank highlighted if the run just played
+// made the table.
+func drawGameOver(c canvas, pal palette, msg string, table []scores.Entry, rank int, bottom float64) {
+	lines := strings.Split(msg, "\n")
+	rows := scoreRows(table)
+
+	lineHeight := hudFace.Metrics().Height.Ceil()
+	ascent := hudFace.Metrics().Ascent.Ceil()
+	width := 0
+	for _, l := range append(lines, rows...) {
+		width = max(width, text.BoundString(hudFace, l).Dx())
+	}
+	height := len(lines) * lineHeight
+	if len(rows) > 0 {
+		height += (1 + len(rows)) * lineHeight
+	}
+
+	w, _ := c.size()
+	x := (int(w) - width) / 2
+	y := max(12, (int(bottom)-height)/2) + ascent
+
+	ink := pal.Ink
+	for _, l := range lines {
+		c.text(l, x, y, ink)
+		y += lineHeight
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	y += lineHeight
+	for i, row := range rows {
+		col := pal.InkSoft
+		switch {
+		case i == 0:
+			col = ink
+		case i-1 == rank:
+			c.rect(float64(x-4), float64(y-ascent-1), float64(width+8), float64(lineHeight), pal.Highlight)
+			col = pal.HighlightInk
+		}
+		c.text(row, x, y, col)
+		y += lineHeight
+	}
+}
+
+// scoreRows formats the high-score table under a header row, or returns
+// nothing if it is empty.
+func scoreRows(table []scores.Entry) []string {
+	if len(table) == 0 {
+		return nil
+	}
+	rows := []string{"  #  SCORE    TIME   SIZE  MODE     DATE"}
+	for i, e := range table {
+		rows = append(rows, fmt.Sprintf("%3d  %5d  %5.1fs  %5.0f  %-7s  %s",
+			i+1, e.Score, e.Survival, e.MaxSize, e.Mode, e.Date.Local().Format("2006-01-02")))
+	}
+	return rows
+}
@@ -0,0 +1,248 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/jdefrancesco/squares/internal/input"
+	"github.com/jdefrancesco/squares/internal/settings"
+	"github.com/jdefrancesco/squares/internal/sim"
+	"github.com/jdefrancesco/squares/internal/ui"
+)
+
+// SetupWindow sizes and configures the window as s asks, before the game
+// runs. Closing the window ends the game through Update, so a run in
+// progress gets its replay saved.
+func SetupWindow(s settings.Settings) {
+	applyWindow(s, true)
+	ebiten.SetWindowClosingHandled(true)
+}
+
+// applyWindow puts the window settings of s into effect, resizing the
+// window only if resize is set so a size the player dragged it to
+// survives other changes. The game updates, and so changes what it draws,
+// at most FPSCap times a second.
+func applyWindow(s settings.Settings, resize bool) {
+	if resize {
+		ebiten.SetWindowSize(int(ScreenWidth*s.WindowScale), int(ScreenHeight*s.WindowScale))
+	}
+	ebiten.SetFullscreen(s.Fullscreen)
+	ebiten.SetVsyncEnabled(s.VSync)
+	tps := ebiten.SyncWithFPS
+	if s.FPSCap > 0 {
+		tps = s.FPSCap
+	}
+	ebiten.SetTPS(tps)
+}
+
+// UseSettings applies s at once, window included. Changes made on the
+// settings screen are saved to path, unless it is empty.
+func (g *Game) UseSettings(s settings.Settings, path string) {
+	g.settingsPath = path
+	g.changeSettings(s)
+}
+
+// changeSettings switches to s and puts it into effect.
+func (g *Game) changeSettings(s settings.Settings) {
+	old := g.settings
+	g.settings = s
+	g.configure()
+	applyWindow(s, s.WindowScale != old.WindowScale)
+}
+
+// configure points input and drawing at the current settings.
+func (g *Game) configure() {
+	s := g.settings
+	g.ctl = newControls(s)
+	g.pad.Bind(s.Bindings.PadBindings())
+	cfg := g.padCfg
+	cfg.Sensitivity = s.StickSensitivity
+	g.pad.Configure(cfg)
+
+	g.look = lookFor(s)
+	g.ui.Style.On = g.look.lang.tr("On")
+	g.ui.Style.Off = g.look.lang.tr("Off")
+}
+
+// saveSettings writes the settings back to their file, if they have one.
+func (g *Game) saveSettings() {
+	if g.settingsPath == "" {
+		return
+	}
+	if err := settings.Save(g.settingsPath, g.settings); err != nil {
+		log.Printf("saving settings: %v", err)
+	}
+}
+
+// settingsScene changes the settings, and how the next run is played,
+// then goes back to the scene it was opened from. Settings apply as they
+// change and are saved on the way out.
+type settingsScene struct {
+	back    scene
+	tab     int
+	changed bool
+}
+
+var settingsTabs = []string{"Game", "Video", "Audio", "Controls", "Display"}
+
+// worldScales are the world sizes on offer, in windows across.
+var worldScales = []float64{1, 2, 4, 8}
+
+func newSettingsScene(back scene) *settingsScene {
+	return &settingsScene{back: back}
+}
+
+func (s *settingsScene) enter(g *Game) {}
+
+func (s *settingsScene) exit(g *Game) {
+	if s.changed {
+		g.saveSettings()
+	}
+}
+
+// settingsRows is how many rows the tallest tab has.
+const settingsRows = 5
+
+func (s *settingsScene) update(g *Game, in ui.Input, frame float64) error {
+	u := g.ui
+	u.Begin(in, g.screen())
+	defer u.End()
+
+	u.Panel(u.Screen())
+	l := g.look.lang
+	row := u.RowHeight()
+	h := 2*row + 8 + ui.StackHeight(settingsRows+2, row, menuGap) + 2*menuGap
+	st := ui.VStack(ui.Centered(u.Screen(), 2*menuWidth, h), menuGap)
+	u.Heading(st.Row(2*row), l.tr("SETTINGS"), 2)
+	tabs := st.Row(row).Columns(len(settingsTabs), menuGap)
+	for i, t := range settingsTabs {
+		if u.Tab("tab-"+t, tabs[i], l.tr(t), i == s.tab) {
+			s.tab = i
+		}
+	}
+	st.Space(8)
+
+	page := ui.VStack(st.Row(ui.StackHeight(settingsRows, row, menuGap)), menuGap)
+	next := g.settings
+	switch s.tab {
+	case 0:
+		s.gamePage(g, page, &next)
+	case 1:
+		videoPage(u, page, l, &next)
+	case 2:
+		audioPage(u, page, l, &next)
+	case 3:
+		s.controlsPage(g, page, &next)
+	case 4:
+		displayPage(u, page, l, &next)
+	}
+	if !next.Equal(g.settings) {
+		g.changeSettings(next)
+		s.changed = true
+	}
+
+	if u.Button("back", st.Row(row), l.tr("Back")) || u.Pressed(input.Back) || u.Pressed(input.Pause) {
+		g.goTo(s.back)
+	}
+	return nil
+}
+
+// gamePage sets up the next run and the language.
+func (s *settingsScene) gamePage(g *Game, st *ui.Stack, next *settings.Settings) {
+	u, l, row := g.ui, g.look.lang, g.ui.RowHeight()
+
+	mode := g.mode
+	if mode == "" {
+		mode = sim.ModeClassic
+	}
+	modes := sim.Modes()
+	choose(u, "mode", st.Row(row), l.tr("Mode"), modes, names(modes, func(m sim.Mode) string { return string(m) }), &mode)
+	g.mode = mode
+	choose(u, "world", st.Row(row), l.tr("World"), worldScales, names(worldScales, func(w float64) string { return fmt.Sprintf("%gx", w) }), &g.worldScale)
+	u.Hint(st.Row(row), l.tr("Changes apply from the next run."))
+
+	tags := names(languages, func(l language) string { return l.tag })
+	choose(u, "language", st.Row(row), l.tr("Language"), tags, names(languages, func(l language) string { return l.name }), &next.Language)
+}
+
+func videoPage(u *ui.Context, st *ui.Stack, l language, next *settings.Settings) {
+	row := u.RowHeight()
+	u.Toggle("fullscreen", st.Row(row), l.tr("Fullscreen"), &next.Fullscreen)
+	choose(u, "window", st.Row(row), l.tr("Window size"), settings.WindowScales,
+		names(settings.WindowScales, func(s float64) string { return fmt.Sprintf("%gx", s) }), &next.WindowScale)
+	u.Toggle("vsync", st.Row(row), l.tr("Vsync"), &next.VSync)
+	choose(u, "fps", st.Row(row), l.tr("FPS cap"), settings.FPSCaps, names(settings.FPSCaps, func(n int) string {
+		if n == 0 {
+			return l.tr("No limit")
+		}
+		return fmt.Sprint(n)
+	}), &next.FPSCap)
+}
+
+// audioPage sets the volumes, kept for when the game has sound.
+func audioPage(u *ui.Context, st *ui.Stack, l language, next *settings.Settings) {
+	row := u.RowHeight()
+	u.Slider("master", st.Row(row), l.trf("Master volume %.0f%%", 100*next.MasterVolume), &next.MasterVolume, 0, 1, 0.1)
+	u.Slider("music", st.Row(row), l.trf("Music volume %.0f%%", 100*next.MusicVolume), &next.MusicVolume, 0, 1, 0.1)
+	u.Slider("effects", st.Row(row), l.trf("Effects volume %.0f%%", 100*next.EffectsVolume), &next.EffectsVolume, 0, 1, 0.1)
+	u.Hint(st.Row(row), l.tr("The game has no sound yet."))
+}
+
+// controlsPage sets what steers and how fast, and leads to the bindings.
+func (s *settingsScene) controlsPage(g *Game, st *ui.Stack, next *settings.Settings) {
+	u, l, row := g.ui, g.look.lang, g.ui.RowHeight()
+	schemes := map[settings.Scheme]string{
+		settings.SchemeAuto:  "Anything",
+		settings.SchemeMouse: "Mouse",
+		settings.SchemeKeys:  "Keys and gamepad",
+	}
+	choose(u, "controls", st.Row(row), l.tr("Steer with"), settings.Schemes(),
+		names(settings.Schemes(), func(sc settings.Scheme) string { return l.tr(schemes[sc]) }), &next.Controls)
+	u.Slider("mouse", st.Row(row), l.trf("Mouse speed %gx", next.MouseSensitivity),
+		&next.MouseSensitivity, settings.MinSensitivity, settings.MaxSensitivity, 0.25)
+	u.Slider("stick", st.Row(row), l.trf("Stick speed %gx", next.StickSensitivity),
+		&next.StickSensitivity, settings.MinSensitivity, settings.MaxSensitivity, 0.25)
+	if u.Button("bindings", st.Row(row), l.tr("Keys and buttons...")) {
+		g.goTo(newBindingsScene(s))
+	}
+}
+
+func displayPage(u *ui.Context, st *ui.Stack, l language, next *settings.Settings) {
+	row := u.RowHeight()
+	themes := map[settings.Theme]string{
+		settings.ThemeLight: "Light",
+		settings.ThemeDark:  "Dark",
+	}
+	choose(u, "theme", st.Row(row), l.tr("Theme"), settings.Themes(),
+		names(settings.Themes(), func(t settings.Theme) string { return l.tr(themes[t]) }), &next.Theme)
+	u.Toggle("colorblind", st.Row(row), l.tr("Colorblind colors"), &next.Colorblind)
+	u.Toggle("hud", st.Row(row), l.tr("HUD"), &next.ShowHUD)
+	u.Toggle("minimap", st.Row(row), l.tr("Minimap"), &next.ShowMinimap)
+	u.Toggle("fps", st.Row(row), l.tr("FPS counter"), &next.ShowFPS)
+}
+
+// choose runs a dropdown picking *v out of values, which it shows as
+// labels.
+func choose[T comparable](u *ui.Context, id string, r ui.Rect, label string, values []T, labels []string, v *T) {
+	i := slices.Index(values, *v)
+	if u.Dropdown(id, r, label, labels, &i) {
+		*v = values[i]
+	}
+}
+
+// names labels each of values with name.
+func names[T any](values []T, name func(T) string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = name(v)
+	}
+	return out
+}
+
+func (s *settingsScene) draw(g *Game, c canvas) {
+	drawWorld(c, g.view(), g.world, &g.trail, g.look)
+	g.ui.Draw(uiPainter{c})
+}
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/jdefrancesco/squares/internal/settings"
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// palette is every color the game draws with that the theme and the
+// colorblind setting change. Menus and toasts keep their own dark boxes
+// in every theme.
+type palette struct {
+	Background color.RGBA
+	Arena      color.RGBA
+	Grid       color.RGBA
+
+	Player color.RGBA
+	Square color.RGBA
+	Hazard color.RGBA
+	Boost  color.RGBA
+	// Rings around the player while invincible and mid-dash.
+	Invincible color.RGBA
+	Dash       color.RGBA
+
+	// Ink is text and outlines; InkSoft is for secondary text. Shade is
+	// a translucent wash behind HUD pieces.
+	Ink     color.RGBA
+	InkSoft color.RGBA
+	Shade   color.RGBA
+
+	// Highlight marks the latest run in the high-score table.
+	Highlight    color.RGBA
+	HighlightInk color.RGBA
+}
+
+var lightPalette = palette{
+	Background:   color.RGBA{225, 225, 225, 255},
+	Arena:        color.RGBA{245, 245, 245, 255},
+	Grid:         color.RGBA{232, 232, 232, 255},
+	Player:       color.RGBA{35, 145, 85, 255},
+	Square:       color.RGBA{0, 0, 0, 255},
+	Hazard:       color.RGBA{15, 15, 15, 255},
+	Boost:        color.RGBA{40, 150, 165, 255},
+	Invincible:   color.RGBA{40, 150, 165, 220},
+	Dash:         color.RGBA{120, 120, 120, 200},
+	Ink:          color.RGBA{20, 20, 20, 255},
+	InkSoft:      color.RGBA{60, 60, 60, 255},
+	Shade:        color.RGBA{0, 0, 0, 40},
+	Highlight:    color.RGBA{35, 145, 85, 60},
+	HighlightInk: color.RGBA{20, 90, 50, 255},
+}
+
+// darkPalette turns the arena dark and the squares light. Black hazards
+// would vanish on it, so they turn red.
+var darkPalette = palette{
+	Background:   color.RGBA{18, 18, 22, 255},
+	Arena:        color.RGBA{34, 34, 40, 255},
+	Grid:         color.RGBA{44, 44, 52, 255},
+	Player:       color.RGBA{60, 190, 115, 255},
+	Square:       color.RGBA{235, 235, 235, 255},
+	Hazard:       color.RGBA{200, 60, 60, 255},
+	Boost:        color.RGBA{60, 180, 195, 255},
+	Invincible:   color.RGBA{60, 180, 195, 220},
+	Dash:         color.RGBA{160, 160, 160, 200},
+	Ink:          color.RGBA{235, 235, 235, 255},
+	InkSoft:      color.RGBA{170, 170, 175, 255},
+	Shade:        color.RGBA{255, 255, 255, 40},
+	Highlight:    color.RGBA{60, 190, 115, 70},
+	HighlightInk: color.RGBA{150, 230, 180, 255},
+}
+
+// paletteFor is the palette for theme. The colorblind palette replaces
+// the player's green and the power-up's teal, which red-green
+// colorblindness can blur together, with orange and sky blue.
+func paletteFor(theme settings.Theme, colorblind bool) palette {
+	p := lightPalette
+	if theme == settings.ThemeDark {
+		p = darkPalette
+	}
+	if !colorblind {
+		return p
+	}
+	p.Player = color.RGBA{230, 159, 0, 255}
+	p.Boost = color.RGBA{86, 180, 233, 255}
+	p.Invincible = color.RGBA{86, 180, 233, 220}
+	p.Highlight = color.RGBA{230, 159, 0, 70}
+	p.HighlightInk = color.RGBA{140, 90, 0, 255}
+	if theme == settings.ThemeDark {
+		p.Hazard = color.RGBA{204, 121, 167, 255}
+		p.HighlightInk = color.RGBA{240, 190, 90, 255}
+	}
+	return p
+}
+
+// entity is the color to draw an entity of kind k.
+func (p palette) entity(k sim.Kind) color.RGBA {
+	switch k {
+	case sim.KindCircleHazard:
+		return p.Hazard
+	case sim.KindCircleBoost:
+		return p.Boost
+	}
+	return p.Square
+}
+
+// fade is c at alpha times its opacity, premultiplied as Ebiten expects.
+func fade(c color.RGBA, alpha float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(c.R) * alpha),
+		G: uint8(float64(c.G) * alpha),
+		B: uint8(float64(c.B) * alpha),
+		A: uint8(float64(c.A) * alpha),
+	}
+}
+
+// look is how the settings have the game drawn.
+type look struct {
+	pal  palette
+	lang language
+
+	hud     bool
+	minimap bool
+	fps     bool
+}
+
+func lookFor(s settings.Settings) look {
+	return look{
+		pal:     paletteFor(s.Theme, s.Colorblind),
+		lang:    languageFor(s.Language),
+		hud:     s.ShowHUD,
+		minimap: s.ShowMinimap,
+		fps:     s.ShowFPS,
+	}
+}
@@ -0,0 +1,36 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"github.com/jdefrancesco/squares/internal/input"
+)
+
+// touchButtons are the on-screen buttons for the current scene: dash and
+// pause while playing, none in menus, which are tapped directly.
+func (g *Game) touchButtons() []input.TouchButton {
+	if _, ok := g.scene.(*playScene); !ok || g.next != nil {
+		return nil
+	}
+	w, h := g.win.w, g.win.h
+	return []input.TouchButton{
+		{Action: input.Pause, X: w - 32, Y: 32, R: 22},
+		{Action: input.Dash, X: w - 72, Y: h - 72, R: 44},
+	}
+}
+
+var touchLabels = map[input.Action]string{
+	input.Dash:  "DASH",
+	input.Pause: "II",
+}
+
+func drawTouchButtons(c canvas, pal palette, buttons []input.TouchButton) {
+	for _, b := range buttons {
+		c.circle(b.X, b.Y, b.R, pal.Shade)
+		c.ring(b.X, b.Y, b.R, 2, fade(pal.Ink, 140.0/255))
+
+		label := touchLabels[b.Action]
+		bounds := text.BoundString(hudFace, label)
+		c.text(label, int(b.X)-bounds.Dx()/2, int(b.Y)+bounds.Dy()/2-1, fade(pal.Ink, 220.0/255))
+	}
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// afterImage is a fading copy of the player left behind by a dash.
+type afterImage struct {
+	x, y, size, angle float64
+	left              float64
+}
+
+// dashTrail collects after-images while the player dashes.
+type dashTrail struct {
+	imgs []afterImage
+}
+
+// update ages the trail by one tick and, mid-dash, adds the player's
+// current pose to it.
+func (t *dashTrail) update(w *sim.World) {
+	kept := t.imgs[:0]
+	for _, img := range t.imgs {
+		img.left -= sim.TickDT
+		if img.left > 0 {
+			kept = append(kept, img)
+		}
+	}
+	t.imgs = kept
+
+	if w.Dashing() {
+		p := w.Player()
+		t.imgs = append(t.imgs, afterImage{x: p.X, y: p.Y, size: p.Size, angle: w.Angle(), left: trailDuration})
+	}
+}
+
+func (t *dashTrail) reset() {
+	t.imgs = t.imgs[:0]
+}
+
+func (t *dashTrail) draw(wc canvas, c color.RGBA) {
+	for _, img := range t.imgs {
+		fade := img.left / trailDuration
+		col := c
+		col.A = uint8(float64(trailAlpha) * fade)
+		// Premultiplied alpha, as Ebiten expects.
+		col.R = uint8(float64(c.R) * float64(col.A) / 255)
+		col.G = uint8(float64(c.G) * float64(col.A) / 255)
+		col.B = uint8(float64(c.B) * float64(col.A) / 255)
+		wc.square(img.x, img.y, img.size, img.angle, col)
+	}
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// window tracks the size of the window in logical pixels and how many
+// physical pixels each one has, as last reported to Layout.
+type window struct {
+	w, h float64
+	dpi  float64
+}
+
+func newWindow() window {
+	return window{w: ScreenWidth, h: ScreenHeight, dpi: 1}
+}
+
+// layout records the window size and returns a screen with a pixel for
+// every physical pixel, so drawing stays sharp on high-DPI displays.
+func (win *window) layout(outsideW, outsideH int) (int, int) {
+	win.w, win.h = float64(outsideW), float64(outsideH)
+	win.dpi = 1
+	if m := ebiten.Monitor(); m != nil && m.DeviceScaleFactor() > 0 {
+		win.dpi = m.DeviceScaleFactor()
+	}
+	return int(math.Ceil(win.w * win.dpi)), int(math.Ceil(win.h * win.dpi))
+}
+
+// view maps world coordinates to logical window pixels: a uniform scale
+// and then an offset.
+type view struct {
+	scale      float64
+	offX, offY float64
+}
+
+// cameraView shows what w's camera sees as large as the window allows,
+// centered. Once the window matches the camera's view, as it does for
+// every new run, this is just the camera's scroll.
+func cameraView(w *sim.World, win window) view {
+	vw, vh := w.ViewSize()
+	cx, cy := w.Camera()
+	s := math.Min(win.w/vw, win.h/vh)
+	return view{scale: s, offX: win.w/2 - cx*s, offY: win.h/2 - cy*s}
+}
+
+func (v view) toWorld(x, y float64) (float64, float64) {
+	return (x - v.offX) / v.scale, (y - v.offY) / v.scale
+}
+
+// on returns a canvas that draws on c in world units.
+func (v view) on(c canvas) canvas {
+	return c.within(v.offX, v.offY, v.scale)
+}
+
+// toggleFullscreen flips fullscreen on F11 or Alt+Enter, reporting
+// whether it did.
+func toggleFullscreen() bool {
+	alt := ebiten.IsKeyPressed(ebiten.KeyAltLeft) || ebiten.IsKeyPressed(ebiten.KeyAltRight)
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) || (alt && inpututil.IsKeyJustPressed(ebiten.KeyEnter)) {
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+		return true
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"os"
+	"time"
+)
+
+const tuningPollInterval = 500 * time.Millisecond
+
+// tuningWatcher notices when a tuning file changes on disk. Stat'ing one
+// small file twice a second is cheap and behaves the same everywhere,
+// which a filesystem notification API would not.
+type tuningWatcher struct {
+	path string
+	mod  time.Time
+	size int64
+	next time.Time
+}
+
+func newTuningWatcher(path string, now time.Time) *tuningWatcher {
+	w := &tuningWatcher{path: path}
+	if fi, err := os.Stat(path); err == nil {
+		w.mod, w.size = fi.ModTime(), fi.Size()
+	}
+	w.next = now.Add(tuningPollInterval)
+	return w
+}
+
+// changed reports whether the file was modified since the last time it
+// returned true. A file that is missing or mid-save is retried later.
+func (w *tuningWatcher) changed(now time.Time) bool {
+	if now.Before(w.next) {
+		return false
+	}
+	w.next = now.Add(tuningPollInterval)
+
+	fi, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+	if fi.ModTime().Equal(w.mod) && fi.Size() == w.size {
+		return false
+	}
+	w.mod, w.size = fi.ModTime(), fi.Size()
+	return true
+}
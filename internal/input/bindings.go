@@ -0,0 +1,341 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// actionNames names each action for files and menus, in the order menus
+// list them.
+var actionNames = []struct {
+	action Action
+	name   string
+}{
+	{Dash, "dash"},
+	{Pause, "pause"},
+	{Restart, "restart"},
+	{Quit, "quit"},
+	{Up, "up"},
+	{Down, "down"},
+	{Left, "left"},
+	{Right, "right"},
+	{Confirm, "confirm"},
+	{Back, "back"},
+	{Click, "click"},
+}
+
+// Actions lists every action on its own.
+func Actions() []Action {
+	out := make([]Action, len(actionNames))
+	for i, an := range actionNames {
+		out[i] = an.action
+	}
+	return out
+}
+
+// String is the name of a single action, or the names of a set joined
+// with "+".
+func (a Action) String() string {
+	var parts []string
+	for _, an := range actionNames {
+		if a.Has(an.action) {
+			parts = append(parts, an.name)
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, "+")
+}
+
+// ParseAction reads the name of a single action.
+func ParseAction(name string) (Action, bool) {
+	for _, an := range actionNames {
+		if an.name == name {
+			return an.action, true
+		}
+	}
+	return 0, false
+}
+
+// Device is a kind of input device a control is on.
+type Device string
+
+const (
+	Keyboard Device = "key"
+	Mouse    Device = "mouse"
+	Pad      Device = "pad"
+)
+
+// Control is one key or button. Keys and mouse buttons are named as the
+// front end names them; gamepad buttons by PadButton.String.
+type Control struct {
+	Device Device
+	Name   string
+}
+
+// Key, MouseButton and PadControl make controls.
+func Key(name string) Control         { return Control{Keyboard, name} }
+func MouseButton(name string) Control { return Control{Mouse, name} }
+func PadControl(b PadButton) Control  { return Control{Pad, b.String()} }
+
+// String is the control as it is written in files, such as "key:Space".
+func (c Control) String() string {
+	return string(c.Device) + ":" + c.Name
+}
+
+// ParseControl reads a control written by String.
+func ParseControl(s string) (Control, error) {
+	dev, name, ok := strings.Cut(s, ":")
+	if !ok || name == "" {
+		return Control{}, fmt.Errorf("input: control %q is not device:name", s)
+	}
+	c := Control{Device(dev), name}
+	switch c.Device {
+	case Keyboard, Mouse:
+	case Pad:
+		if _, ok := ParsePadButton(name); !ok {
+			return Control{}, fmt.Errorf("input: unknown gamepad button %q", name)
+		}
+	default:
+		return Control{}, fmt.Errorf("input: unknown device %q", dev)
+	}
+	return c, nil
+}
+
+func (c Control) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Control) UnmarshalText(text []byte) error {
+	v, err := ParseControl(string(text))
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+// Bindings maps each action to the controls that press it. Several
+// controls can press one action, and one control can press several
+// actions as long as they are never wanted at the same time; see
+// Conflicts.
+//
+// Bindings are shared between copies, so they are never changed in
+// place: With, Without and Reset return changed copies.
+type Bindings map[Action][]Control
+
+// defaultKeys are the keyboard and mouse half of DefaultBindings. Space
+// and Escape each do one thing in play and another in menus.
+var defaultKeys = []struct {
+	control Control
+	action  Action
+}{
+	{Key("Space"), Dash},
+	{MouseButton("Left"), Dash},
+	{Key("P"), Pause},
+	{Key("Escape"), Pause},
+	{Key("R"), Restart},
+	{Key("Q"), Quit},
+
+	{Key("ArrowUp"), Up},
+	{Key("W"), Up},
+	{Key("ArrowDown"), Down},
+	{Key("S"), Down},
+	{Key("ArrowLeft"), Left},
+	{Key("A"), Left},
+	{Key("ArrowRight"), Right},
+	{Key("D"), Right},
+	{Key("Enter"), Confirm},
+	{Key("Space"), Confirm},
+	{Key("Escape"), Back},
+	{Key("Backspace"), Back},
+	{MouseButton("Left"), Click},
+}
+
+// DefaultBindings are the controls out of the box: the keys above, the
+// left mouse button and DefaultPadBindings.
+func DefaultBindings() Bindings {
+	b := Bindings{}
+	for _, d := range defaultKeys {
+		b[d.action] = append(b[d.action], d.control)
+	}
+	for _, p := range DefaultPadBindings {
+		b[p.Action] = append(b[p.Action], PadControl(p.Button))
+	}
+	return b
+}
+
+// Actions is every action c presses.
+func (b Bindings) Actions(c Control) Action {
+	var a Action
+	for act, cs := range b {
+		if slices.Contains(cs, c) {
+			a |= act
+		}
+	}
+	return a
+}
+
+// On lists the controls bound to a on device, in the order they were
+// bound.
+func (b Bindings) On(a Action, dev Device) []Control {
+	var out []Control
+	for _, c := range b[a] {
+		if c.Device == dev {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// With is b with c also pressing a.
+func (b Bindings) With(a Action, c Control) Bindings {
+	if slices.Contains(b[a], c) {
+		return b
+	}
+	out := b.clone()
+	out[a] = append(slices.Clip(out[a]), c)
+	return out
+}
+
+// Set is b with c as a's i'th control in place of whatever was there,
+// or added at the end if a has no more than i controls.
+func (b Bindings) Set(a Action, i int, c Control) Bindings {
+	out := b.clone()
+	cs := slices.Clone(out[a])
+	if i < len(cs) {
+		cs[i] = c
+	} else {
+		cs = append(cs, c)
+	}
+	out[a] = cs
+	return out
+}
+
+// Without is b with c no longer pressing any of actions.
+func (b Bindings) Without(actions Action, c Control) Bindings {
+	out := b.clone()
+	for a, cs := range out {
+		if actions.Has(a) {
+			out[a] = slices.DeleteFunc(slices.Clone(cs), func(x Control) bool { return x == c })
+		}
+	}
+	return out
+}
+
+// Reset is b with a's controls back to the defaults.
+func (b Bindings) Reset(a Action) Bindings {
+	out := b.clone()
+	out[a] = DefaultBindings()[a]
+	return out
+}
+
+func (b Bindings) clone() Bindings {
+	out := maps.Clone(b)
+	if out == nil {
+		out = Bindings{}
+	}
+	return out
+}
+
+// Equal reports whether b and o bind the same controls to every action,
+// in the same order.
+func (b Bindings) Equal(o Bindings) bool {
+	for _, a := range Actions() {
+		if !slices.Equal(b[a], o[a]) {
+			return false
+		}
+	}
+	return true
+}
+
+// contexts are the sets of actions that are read at the same time: those
+// for playing and those for menus. Sharing a control between two actions
+// in one context would press both at once; sharing it across contexts, as
+// Space does for Dash and Confirm, is fine. Quit is read everywhere, and
+// menus read Pause and Restart as ways out.
+var contexts = []Action{
+	Dash | Pause | Restart | Quit | Up | Down | Left | Right,
+	Up | Down | Left | Right | Confirm | Back | Click | Pause | Restart | Quit,
+}
+
+// synonyms are actions that mean the same wherever they are read
+// together: menus leave on Pause or Back alike, so Escape can be both.
+var synonyms = []Action{Pause | Back}
+
+// Conflicts is every action other than a that c already presses and that
+// is wanted at the same time as a, so binding c to a as well would press
+// both.
+func (b Bindings) Conflicts(a Action, c Control) Action {
+	var near Action
+	for _, ctx := range contexts {
+		if ctx.Has(a) {
+			near |= ctx
+		}
+	}
+	clash := b.Actions(c) & near &^ a
+	for _, syn := range synonyms {
+		if syn.Has(a) {
+			clash &^= syn
+		}
+	}
+	return clash
+}
+
+// Clashing is every action with a control that conflicts with another
+// action.
+func (b Bindings) Clashing() Action {
+	var out Action
+	for a, cs := range b {
+		for _, c := range cs {
+			if b.Conflicts(a, c) != 0 {
+				out |= a
+			}
+		}
+	}
+	return out
+}
+
+// MarshalJSON writes bindings as an object of action names.
+func (b Bindings) MarshalJSON() ([]byte, error) {
+	m := map[string][]Control{}
+	for a, cs := range b {
+		m[a.String()] = cs
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON replaces the controls of each action in the data, leaving
+// the rest as they were, so actions a file does not mention keep their
+// defaults. Actions this build does not know are ignored.
+func (b *Bindings) UnmarshalJSON(data []byte) error {
+	var m map[string][]Control
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	out := b.clone()
+	for name, cs := range m {
+		if a, ok := ParseAction(name); ok {
+			out[a] = cs
+		}
+	}
+	*b = out
+	return nil
+}
+
+// PadBindings is the gamepad half of b, for Gamepad.
+func (b Bindings) PadBindings() []PadBinding {
+	var out []PadBinding
+	for _, a := range Actions() {
+		for _, c := range b.On(a, Pad) {
+			if btn, ok := ParsePadButton(c.Name); ok {
+				out = append(out, PadBinding{btn, a})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package input
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestDefaultBindingsHaveNoConflicts(t *testing.T) {
+	b := DefaultBindings()
+	for _, a := range Actions() {
+		for _, c := range b[a] {
+			if clash := b.Conflicts(a, c); clash != 0 {
+				t.Errorf("%v on %v clashes with %v", c, a, clash)
+			}
+		}
+	}
+	if got := b.Clashing(); got != 0 {
+		t.Errorf("expected no clashing actions, got %v", got)
+	}
+	if got := b.PadBindings(); !slices.Equal(got, DefaultPadBindings) {
+		t.Fatalf("expected the default gamepad bindings, got %v", got)
+	}
+}
+
+func TestBindingConflicts(t *testing.T) {
+	b := DefaultBindings()
+	if got := b.Conflicts(Dash, Key("Q")); got != Quit {
+		t.Fatalf("expected Q on dash to clash with quit, got %v", got)
+	}
+	// Enter confirms in menus, which is never at the same time as dashing.
+	if got := b.Conflicts(Dash, Key("Enter")); got != 0 {
+		t.Fatalf("expected Enter on dash not to clash, got %v", got)
+	}
+	// Quit is read in every scene, and menus read Pause as a way out.
+	if got := b.Conflicts(Quit, Key("Enter")); got != Confirm {
+		t.Fatalf("expected Enter on quit to clash with confirm, got %v", got)
+	}
+	if got := b.Conflicts(Confirm, Key("Q")); got != Quit {
+		t.Fatalf("expected Q on confirm to clash with quit, got %v", got)
+	}
+	if got := b.Conflicts(Pause, Key("Enter")); got != Confirm {
+		t.Fatalf("expected Enter on pause to clash with confirm, got %v", got)
+	}
+	if got := b.Conflicts(Up, Key("Escape")); got != Pause|Back {
+		t.Fatalf("expected Escape on up to clash with pause and back, got %v", got)
+	}
+	if got := b.With(Quit, Key("Escape")).Clashing(); got != Quit|Pause|Back {
+		t.Fatalf("expected Escape on quit to make quit, pause and back clash, got %v", got)
+	}
+}
+
+func TestBindingsAreCopiedOnChange(t *testing.T) {
+	b := DefaultBindings()
+	left := b.With(Dash, MouseButton("Right")).Without(Dash, MouseButton("Left"))
+
+	if !b.Equal(DefaultBindings()) {
+		t.Fatalf("expected the original bindings untouched")
+	}
+	if got := left.On(Dash, Mouse); !slices.Equal(got, []Control{MouseButton("Right")}) {
+		t.Fatalf("expected dash on the right button only, got %v", got)
+	}
+	swapped := b.Set(Quit, 0, Key("F10"))
+	if !slices.Equal(swapped[Quit], []Control{Key("F10")}) || !slices.Equal(b[Quit], []Control{Key("Q")}) {
+		t.Fatalf("expected set to replace Q with F10 in a copy, got %v and %v", swapped[Quit], b[Quit])
+	}
+	if got := left.Reset(Dash); !got.Equal(b) {
+		t.Fatalf("expected reset to restore the defaults, got %v", got[Dash])
+	}
+}
+
+func TestBindingsJSON(t *testing.T) {
+	b := DefaultBindings().Without(Quit, Key("Q")).With(Quit, Key("F10"))
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	got := Bindings{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !got.Equal(b) {
+		t.Fatalf("round trip gave %s", data)
+	}
+
+	// Actions missing from the file keep what they had, and unknown ones
+	// are skipped.
+	got = DefaultBindings()
+	if err := json.Unmarshal([]byte(`{"dash": ["key:Z", "pad:West"], "teleport": ["key:T"]}`), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if want := []Control{Key("Z"), PadControl(PadWest)}; !slices.Equal(got[Dash], want) {
+		t.Fatalf("expected dash on %v, got %v", want, got[Dash])
+	}
+	if !slices.Equal(got[Pause], DefaultBindings()[Pause]) {
+		t.Fatalf("expected pause left at its defaults, got %v", got[Pause])
+	}
+
+	for _, bad := range []string{`{"dash": ["Space"]}`, `{"dash": ["joystick:1"]}`, `{"dash": ["pad:Z"]}`} {
+		if err := json.Unmarshal([]byte(bad), &got); err == nil {
+			t.Errorf("expected %s to be refused", bad)
+		}
+	}
+}
+
+func TestGamepadBind(t *testing.T) {
+	pads := newFakePads()
+	pads.connect(0)
+	g := NewGamepad(pads, DefaultPadConfig)
+	g.Poll()
+
+	g.Bind(DefaultBindings().Without(Dash, PadControl(PadSouth)).With(Dash, PadControl(PadRightShoulder)).PadBindings())
+	pads.buttons[0][PadRightShoulder] = true
+	if s := g.Poll(); s.Held != Dash {
+		t.Fatalf("expected the right shoulder to dash, got %v", s.Held)
+	}
+	pads.buttons[0][PadRightShoulder] = false
+	pads.buttons[0][PadSouth] = true
+	if s := g.Poll(); s.Held != Confirm {
+		t.Fatalf("expected the bottom button to only confirm, got %v", s.Held)
+	}
+}
@@ -0,0 +1,254 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// PadAxis is an axis of a gamepad with the standard layout.
+type PadAxis int
+
+const (
+	PadLeftX PadAxis = iota
+	PadLeftY
+	PadRightX
+	PadRightY
+)
+
+// PadButton is a button of a gamepad with the standard layout, named by
+// position so it means the same on every brand.
+type PadButton int
+
+const (
+	PadSouth PadButton = iota // A on Xbox, Cross on PlayStation
+	PadEast
+	PadWest
+	PadNorth
+	PadLeftShoulder
+	PadRightShoulder
+	PadBack
+	PadStart
+	PadUp
+	PadDown
+	PadLeft
+	PadRight
+)
+
+var padButtonNames = [...]string{
+	PadSouth:         "South",
+	PadEast:          "East",
+	PadWest:          "West",
+	PadNorth:         "North",
+	PadLeftShoulder:  "LeftShoulder",
+	PadRightShoulder: "RightShoulder",
+	PadBack:          "Back",
+	PadStart:         "Start",
+	PadUp:            "Up",
+	PadDown:          "Down",
+	PadLeft:          "Left",
+	PadRight:         "Right",
+}
+
+func (b PadButton) String() string {
+	if b < 0 || int(b) >= len(padButtonNames) {
+		return fmt.Sprintf("PadButton(%d)", int(b))
+	}
+	return padButtonNames[b]
+}
+
+// ParsePadButton reads a button name given by String.
+func ParsePadButton(name string) (PadButton, bool) {
+	i := slices.Index(padButtonNames[:], name)
+	return PadButton(i), i >= 0
+}
+
+// Pads is the gamepad API Gamepad reads from: Ebiten in the game, a fake
+// in tests.
+type Pads interface {
+	// IDs lists the connected gamepads that have the standard layout.
+	IDs() []int
+	// Axis reports an axis in [-1, 1]; down and right are positive.
+	Axis(id int, a PadAxis) float64
+	Pressed(id int, b PadButton) bool
+	// Vibrate rumbles a gamepad, if it can, at strength in [0, 1].
+	Vibrate(id int, strength float64, d time.Duration)
+}
+
+// PadBinding ties a gamepad button to an action.
+type PadBinding struct {
+	Button PadButton
+	Action Action
+}
+
+// DefaultPadBindings dash with the bottom face button, pause with Start and
+// restart with Back. In menus the d-pad moves, the bottom face button
+// confirms and the right one goes back.
+var DefaultPadBindings = []PadBinding{
+	{PadSouth, Dash},
+	{PadStart, Pause},
+	{PadBack, Restart},
+	{PadUp, Up},
+	{PadDown, Down},
+	{PadLeft, Left},
+	{PadRight, Right},
+	{PadSouth, Confirm},
+	{PadEast, Back},
+}
+
+// A stick pushed this far also presses the menu direction it points in.
+const stickMenuTilt = 0.5
+
+// PadConfig tunes how Gamepad reads sticks.
+type PadConfig struct {
+	// Deadzone is the radius, as a fraction of full tilt, within which the
+	// stick reads as centered. Beyond it the range is rescaled so movement
+	// still starts from zero and reaches full speed at full tilt.
+	Deadzone float64
+	// Sensitivity multiplies the tilt past the deadzone, so above 1 the
+	// stick reaches full speed before full tilt. Zero means 1.
+	Sensitivity float64
+}
+
+// DefaultPadConfig suits most worn sticks.
+var DefaultPadConfig = PadConfig{Deadzone: 0.2, Sensitivity: 1}
+
+// Gamepad is a Source that steers with the left stick of every connected
+// standard gamepad. Pads can come and go at any time; a button already
+// down when its pad connects is ignored until released, so plugging in
+// mid-press does not fire an action.
+type Gamepad struct {
+	pads     Pads
+	cfg      PadConfig
+	bindings []PadBinding
+
+	known map[int]bool
+	// stuck holds, per pad, the buttons to ignore until released.
+	stuck map[int]Action
+
+	// OnChange, if set, is told about every pad that connects or
+	// disconnects.
+	OnChange func(id int, connected bool)
+}
+
+func NewGamepad(pads Pads, cfg PadConfig) *Gamepad {
+	return &Gamepad{
+		pads:     pads,
+		cfg:      cfg,
+		bindings: DefaultPadBindings,
+		known:    map[int]bool{},
+		stuck:    map[int]Action{},
+	}
+}
+
+// Bind replaces the button bindings from the next Poll.
+func (g *Gamepad) Bind(bindings []PadBinding) {
+	g.bindings = bindings
+}
+
+// Configure changes the stick settings from the next Poll.
+func (g *Gamepad) Configure(cfg PadConfig) {
+	g.cfg = cfg
+}
+
+func (g *Gamepad) Poll() State {
+	var s State
+
+	ids := g.pads.IDs()
+	g.track(ids)
+	for _, id := range ids {
+		x, y := g.stick(id)
+		s.MoveX += x
+		s.MoveY += y
+		s.Held |= stickMenu(x, y)
+
+		var held Action
+		for _, b := range g.bindings {
+			if g.pads.Pressed(id, b.Button) {
+				held |= b.Action
+			}
+		}
+		g.stuck[id] &= held
+		s.Held |= held &^ g.stuck[id]
+	}
+	s.MoveX = math.Max(-1, math.Min(s.MoveX, 1))
+	s.MoveY = math.Max(-1, math.Min(s.MoveY, 1))
+	return s
+}
+
+// track notices pads connecting and disconnecting.
+func (g *Gamepad) track(ids []int) {
+	for _, id := range ids {
+		if g.known[id] {
+			continue
+		}
+		g.known[id] = true
+		var held Action
+		for _, b := range g.bindings {
+			if g.pads.Pressed(id, b.Button) {
+				held |= b.Action
+			}
+		}
+		g.stuck[id] = held
+		if g.OnChange != nil {
+			g.OnChange(id, true)
+		}
+	}
+	var gone []int
+	for id := range g.known {
+		if !slices.Contains(ids, id) {
+			gone = append(gone, id)
+		}
+	}
+	slices.Sort(gone)
+	for _, id := range gone {
+		delete(g.known, id)
+		delete(g.stuck, id)
+		if g.OnChange != nil {
+			g.OnChange(id, false)
+		}
+	}
+}
+
+// stick reads the left stick of pad id with the radial deadzone applied.
+func (g *Gamepad) stick(id int) (float64, float64) {
+	x := g.pads.Axis(id, PadLeftX)
+	y := g.pads.Axis(id, PadLeftY)
+	mag := math.Hypot(x, y)
+	dz := g.cfg.Deadzone
+	if mag <= dz || mag == 0 {
+		return 0, 0
+	}
+	sens := g.cfg.Sensitivity
+	if sens == 0 {
+		sens = 1
+	}
+	scaled := math.Min(1, (mag-dz)/(1-dz)*sens)
+	return x / mag * scaled, y / mag * scaled
+}
+
+// stickMenu is the menu directions a stick at (x, y) presses.
+func stickMenu(x, y float64) Action {
+	var a Action
+	switch {
+	case y <= -stickMenuTilt:
+		a |= Up
+	case y >= stickMenuTilt:
+		a |= Down
+	}
+	switch {
+	case x <= -stickMenuTilt:
+		a |= Left
+	case x >= stickMenuTilt:
+		a |= Right
+	}
+	return a
+}
+
+// Rumble vibrates every connected pad.
+func (g *Gamepad) Rumble(strength float64, d time.Duration) {
+	for _, id := range g.pads.IDs() {
+		g.pads.Vibrate(id, strength, d)
+	}
+}
@@ -0,0 +1,181 @@
+package input
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// fakePads is a scriptable Pads.
+type fakePads struct {
+	axes    map[int][4]float64
+	buttons map[int]map[PadButton]bool
+	rumbles []float64
+}
+
+func newFakePads() *fakePads {
+	return &fakePads{axes: map[int][4]float64{}, buttons: map[int]map[PadButton]bool{}}
+}
+
+func (f *fakePads) connect(id int) {
+	f.buttons[id] = map[PadButton]bool{}
+}
+
+func (f *fakePads) disconnect(id int) {
+	delete(f.buttons, id)
+	delete(f.axes, id)
+}
+
+func (f *fakePads) IDs() []int {
+	var ids []int
+	for id := range 4 {
+		if _, ok := f.buttons[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (f *fakePads) Axis(id int, a PadAxis) float64             { return f.axes[id][a] }
+func (f *fakePads) Pressed(id int, b PadButton) bool           { return f.buttons[id][b] }
+func (f *fakePads) Vibrate(id int, s float64, _ time.Duration) { f.rumbles = append(f.rumbles, s) }
+
+func TestGamepadStickDeadzone(t *testing.T) {
+	pads := newFakePads()
+	pads.connect(0)
+	g := NewGamepad(pads, PadConfig{Deadzone: 0.2})
+
+	cases := []struct {
+		x, y, wantX, wantY float64
+	}{
+		{0.1, 0.1, 0, 0},   // inside the deadzone
+		{0.2, 0, 0, 0},     // on its edge
+		{0.6, 0, 0.5, 0},   // halfway out
+		{1, 0, 1, 0},       // full tilt
+		{0, -0.6, 0, -0.5}, // radial, not per axis
+	}
+	for _, tc := range cases {
+		pads.axes[0] = [4]float64{tc.x, tc.y}
+		s := g.Poll()
+		if math.Abs(s.MoveX-tc.wantX) > 1e-9 || math.Abs(s.MoveY-tc.wantY) > 1e-9 {
+			t.Fatalf("stick (%v,%v): got (%v,%v), want (%v,%v)", tc.x, tc.y, s.MoveX, s.MoveY, tc.wantX, tc.wantY)
+		}
+	}
+
+	// A diagonal just past the deadzone keeps its direction.
+	pads.axes[0] = [4]float64{0.3, 0.3}
+	s := g.Poll()
+	if math.Abs(s.MoveX-s.MoveY) > 1e-12 || s.MoveX <= 0 {
+		t.Fatalf("expected an even diagonal, got (%v,%v)", s.MoveX, s.MoveY)
+	}
+}
+
+func TestGamepadStickSensitivity(t *testing.T) {
+	pads := newFakePads()
+	pads.connect(0)
+	g := NewGamepad(pads, PadConfig{Deadzone: 0.2, Sensitivity: 2})
+
+	pads.axes[0] = [4]float64{0.4, 0}
+	if s := g.Poll(); math.Abs(s.MoveX-0.5) > 1e-9 {
+		t.Fatalf("expected a quarter tilt to read as half at double sensitivity, got %v", s.MoveX)
+	}
+	pads.axes[0] = [4]float64{0.8, 0}
+	if s := g.Poll(); s.MoveX != 1 {
+		t.Fatalf("expected three quarters to already be full speed, got %v", s.MoveX)
+	}
+}
+
+func TestGamepadButtons(t *testing.T) {
+	pads := newFakePads()
+	pads.connect(0)
+	r := NewReader(NewGamepad(pads, DefaultPadConfig))
+	r.Next()
+
+	pads.buttons[0][PadSouth] = true
+	if f := r.Next(); !f.Pressed.Has(Dash) {
+		t.Fatalf("expected south to dash, got %+v", f)
+	}
+	pads.buttons[0][PadSouth] = false
+	pads.buttons[0][PadStart] = true
+	if f := r.Next(); !f.Pressed.Has(Pause) || f.Held.Has(Dash) {
+		t.Fatalf("expected start to pause, got %+v", f)
+	}
+}
+
+func TestGamepadHotPlug(t *testing.T) {
+	pads := newFakePads()
+	g := NewGamepad(pads, DefaultPadConfig)
+	var events []string
+	g.OnChange = func(id int, connected bool) {
+		if connected {
+			events = append(events, "connect")
+		} else {
+			events = append(events, "disconnect")
+		}
+	}
+
+	if s := g.Poll(); s != (State{}) {
+		t.Fatalf("expected nothing with no pads, got %+v", s)
+	}
+
+	// Plugged in with dash already down: ignored until released.
+	pads.connect(1)
+	pads.buttons[1][PadSouth] = true
+	if s := g.Poll(); s.Held.Has(Dash) {
+		t.Fatalf("expected a button held while connecting to be ignored")
+	}
+	pads.buttons[1][PadSouth] = false
+	g.Poll()
+	pads.buttons[1][PadSouth] = true
+	if s := g.Poll(); !s.Held.Has(Dash) {
+		t.Fatalf("expected the next press to count")
+	}
+
+	pads.disconnect(1)
+	if s := g.Poll(); s.Held != 0 {
+		t.Fatalf("expected a disconnected pad to release its buttons, got %+v", s)
+	}
+	if len(events) != 2 || events[0] != "connect" || events[1] != "disconnect" {
+		t.Fatalf("expected connect then disconnect, got %v", events)
+	}
+}
+
+func TestGamepadRumble(t *testing.T) {
+	pads := newFakePads()
+	pads.connect(0)
+	pads.connect(2)
+	g := NewGamepad(pads, DefaultPadConfig)
+
+	g.Rumble(0.5, 100*time.Millisecond)
+	if len(pads.rumbles) != 2 || pads.rumbles[0] != 0.5 {
+		t.Fatalf("expected both pads to rumble, got %v", pads.rumbles)
+	}
+}
+
+func TestGamepadMenuNavigation(t *testing.T) {
+	pads := newFakePads()
+	pads.connect(0)
+	r := NewReader(NewGamepad(pads, DefaultPadConfig))
+	r.Next()
+
+	pads.buttons[0][PadDown] = true
+	if f := r.Next(); !f.Pressed.Has(Down) {
+		t.Fatalf("expected the d-pad to press down, got %+v", f)
+	}
+	pads.buttons[0][PadDown] = false
+	r.Next()
+
+	// A stick flick counts once, however long it is held.
+	pads.axes[0] = [4]float64{0, -0.9}
+	if f := r.Next(); !f.Pressed.Has(Up) {
+		t.Fatalf("expected the stick to press up, got %+v", f)
+	}
+	if f := r.Next(); f.Pressed.Has(Up) {
+		t.Fatalf("expected a held stick to press up only once, got %+v", f)
+	}
+
+	pads.buttons[0][PadSouth] = true
+	if f := r.Next(); !f.Pressed.Has(Confirm | Dash) {
+		t.Fatalf("expected south to confirm as well as dash, got %+v", f)
+	}
+}
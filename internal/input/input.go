@@ -0,0 +1,128 @@
+// Package input turns the raw state of input devices into per-tick action
+// frames. Device-specific code lives with the front end; this package only
+// knows about the State sources report and small device interfaces such as
+// Pads, so it is usable headless and testable without hardware.
+package input
+
+import "math"
+
+// Action is a set of buttons, one bit per action.
+type Action uint16
+
+const (
+	Dash Action = 1 << iota
+	Pause
+	Restart
+	Quit
+
+	// Menu navigation.
+	Up
+	Down
+	Left
+	Right
+	Confirm
+	Back
+
+	// Click is held while the pointer is down.
+	Click
+)
+
+// Has reports whether every action in b is set in a.
+func (a Action) Has(b Action) bool {
+	return a&b == b
+}
+
+// State is the level of every control on a source at one instant.
+type State struct {
+	// Target is set when X, Y is an absolute position to move to, such as
+	// a cursor that just moved.
+	Target bool
+	X, Y   float64
+
+	// MoveX, MoveY is a movement vector with each component in [-1, 1].
+	MoveX, MoveY float64
+
+	// Pointer is set when PointerX, PointerY is where a cursor or finger
+	// is, for pointing at menus. Unlike a target it is reported every
+	// poll and never offset.
+	Pointer            bool
+	PointerX, PointerY float64
+
+	// Held is every action whose button is currently down.
+	Held Action
+}
+
+// Source is anything that can report input state: a device, a bot, or a
+// script in a test.
+type Source interface {
+	Poll() State
+}
+
+// Frame is one tick of input: the polled state plus the actions whose
+// button went down since the previous frame.
+type Frame struct {
+	State
+	Pressed Action
+}
+
+// Reader polls a Source and does the edge detection for it, so sources
+// only ever report levels.
+type Reader struct {
+	src  Source
+	prev Action
+}
+
+func NewReader(src Source) *Reader {
+	return &Reader{src: src}
+}
+
+// Next polls the source and returns the frame for this tick.
+func (r *Reader) Next() Frame {
+	s := r.src.Poll()
+	f := Frame{State: s, Pressed: s.Held &^ r.prev}
+	r.prev = s.Held
+	return f
+}
+
+// Multi merges several sources into one. Held actions are combined, move
+// vectors are summed and clamped, and the first source with a target or a
+// pointer wins it.
+type Multi []Source
+
+func (m Multi) Poll() State {
+	var out State
+	for _, src := range m {
+		s := src.Poll()
+		out.Held |= s.Held
+		out.MoveX += s.MoveX
+		out.MoveY += s.MoveY
+		if s.Target && !out.Target {
+			out.Target = true
+			out.X, out.Y = s.X, s.Y
+		}
+		if s.Pointer && !out.Pointer {
+			out.Pointer = true
+			out.PointerX, out.PointerY = s.PointerX, s.PointerY
+		}
+	}
+	out.MoveX = math.Max(-1, math.Min(out.MoveX, 1))
+	out.MoveY = math.Max(-1, math.Min(out.MoveY, 1))
+	return out
+}
+
+// Scripted plays back a fixed list of states, one per Poll, and keeps
+// reporting the last one once the script runs out. An empty script
+// reports the zero State.
+type Scripted struct {
+	States []State
+	next   int
+}
+
+func (s *Scripted) Poll() State {
+	if len(s.States) == 0 {
+		return State{}
+	}
+	st := s.States[min(s.next, len(s.States)-1)]
+	s.next++
+	return st
+}
@@ -0,0 +1,54 @@
+package input
+
+import "testing"
+
+func TestReaderReportsPressOnlyOnEdge(t *testing.T) {
+	r := NewReader(&Scripted{States: []State{
+		{},
+		{Held: Dash},
+		{Held: Dash | Pause},
+		{Held: Pause},
+		{},
+		{Held: Dash},
+	}})
+
+	want := []Action{0, Dash, Pause, 0, 0, Dash}
+	for i, w := range want {
+		f := r.Next()
+		if f.Pressed != w {
+			t.Fatalf("frame %d: pressed=%b want %b", i, f.Pressed, w)
+		}
+	}
+}
+
+func TestMultiMergesSources(t *testing.T) {
+	m := Multi{
+		&Scripted{States: []State{{MoveX: 1, Held: Dash}}},
+		&Scripted{States: []State{{MoveX: 1, MoveY: -0.5}}},
+		&Scripted{States: []State{{Target: true, X: 10, Y: 20, Held: Quit}}},
+		&Scripted{States: []State{{Target: true, X: 99, Y: 99, Pointer: true, PointerX: 5, PointerY: 6}}},
+	}
+
+	s := m.Poll()
+	if s.MoveX != 1 || s.MoveY != -0.5 {
+		t.Fatalf("expected clamped move (1,-0.5), got (%v,%v)", s.MoveX, s.MoveY)
+	}
+	if !s.Held.Has(Dash | Quit) {
+		t.Fatalf("expected held dash and quit, got %b", s.Held)
+	}
+	if !s.Target || s.X != 10 || s.Y != 20 {
+		t.Fatalf("expected first target (10,20), got %+v", s)
+	}
+	if !s.Pointer || s.PointerX != 5 || s.PointerY != 6 {
+		t.Fatalf("expected the only pointer (5,6), got %+v", s)
+	}
+}
+
+func TestScriptedHoldsLastState(t *testing.T) {
+	s := &Scripted{States: []State{{X: 1}, {X: 2}}}
+	for i, want := range []float64{1, 2, 2, 2} {
+		if got := s.Poll().X; got != want {
+			t.Fatalf("poll %d: X=%v want %v", i, got, want)
+		}
+	}
+}
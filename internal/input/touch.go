@@ -0,0 +1,148 @@
+package input
+
+import (
+	"math"
+	"slices"
+)
+
+// Touch is one finger on the screen, in screen coordinates.
+type Touch struct {
+	ID   int
+	X, Y float64
+}
+
+// TouchScreen is the touch API TouchSource reads from: Ebiten in the game,
+// a fake in tests.
+type TouchScreen interface {
+	// Touches lists the fingers currently down.
+	Touches() []Touch
+}
+
+// TouchButton is a round on-screen button that holds Action while a finger
+// that started on it stays down.
+type TouchButton struct {
+	Action  Action
+	X, Y, R float64
+}
+
+func (b TouchButton) contains(x, y float64) bool {
+	return math.Hypot(x-b.X, y-b.Y) <= b.R
+}
+
+// TouchConfig tunes TouchSource.
+type TouchConfig struct {
+	// The player is steered to the dragging finger plus this offset, so
+	// the finger does not cover it.
+	OffsetX, OffsetY float64
+}
+
+// DefaultTouchConfig keeps the player a thumb's width above the finger.
+var DefaultTouchConfig = TouchConfig{OffsetY: -60}
+
+// TouchSource is a Source for touchscreens. The first finger down that is
+// not on a button drags the player; a second one alongside it dashes, so a
+// two-finger tap is a dash. Fingers that start on a button only press it.
+// The dragging finger is also the pointer, clicking for as long as it is
+// down.
+type TouchSource struct {
+	screen  TouchScreen
+	cfg     TouchConfig
+	buttons []TouchButton
+
+	// fingers maps every finger down to the action of the button it
+	// started on, or 0 if it is free.
+	fingers  map[int]Action
+	used     bool
+	drag     int
+	dragging bool
+	lastX    float64
+	lastY    float64
+}
+
+func NewTouchSource(screen TouchScreen, cfg TouchConfig) *TouchSource {
+	return &TouchSource{screen: screen, cfg: cfg, fingers: map[int]Action{}}
+}
+
+// Configure changes the drag offset from the next Poll.
+func (t *TouchSource) Configure(cfg TouchConfig) {
+	t.cfg = cfg
+}
+
+// SetButtons replaces the on-screen buttons. Fingers already down keep
+// whatever they were doing.
+func (t *TouchSource) SetButtons(buttons []TouchButton) {
+	t.buttons = buttons
+}
+
+// Buttons returns the on-screen buttons, for drawing.
+func (t *TouchSource) Buttons() []TouchButton {
+	return t.buttons
+}
+
+// Used reports whether the screen has ever been touched, so on-screen
+// buttons can stay hidden on machines nobody touches.
+func (t *TouchSource) Used() bool {
+	return t.used
+}
+
+// Dragging reports whether a finger is steering the player.
+func (t *TouchSource) Dragging() bool {
+	return t.dragging
+}
+
+func (t *TouchSource) Poll() State {
+	var s State
+
+	touches := t.screen.Touches()
+	t.used = t.used || len(touches) > 0
+	down := make([]int, len(touches))
+	for i, tc := range touches {
+		down[i] = tc.ID
+	}
+	for id := range t.fingers {
+		if !slices.Contains(down, id) {
+			delete(t.fingers, id)
+		}
+	}
+	if t.dragging && !slices.Contains(down, t.drag) {
+		t.dragging = false
+	}
+
+	free := 0
+	for _, tc := range touches {
+		a, known := t.fingers[tc.ID]
+		if !known {
+			for _, b := range t.buttons {
+				if b.contains(tc.X, tc.Y) {
+					a = b.Action
+					break
+				}
+			}
+			t.fingers[tc.ID] = a
+		}
+		if a != 0 {
+			s.Held |= a
+			continue
+		}
+
+		free++
+		if !t.dragging {
+			t.dragging, t.drag = true, tc.ID
+			t.lastX, t.lastY = math.NaN(), math.NaN()
+		}
+		if tc.ID == t.drag {
+			s.Pointer = true
+			s.PointerX, s.PointerY = tc.X, tc.Y
+			s.Held |= Click
+		}
+		if tc.ID == t.drag && (tc.X != t.lastX || tc.Y != t.lastY) {
+			s.Target = true
+			s.X, s.Y = tc.X+t.cfg.OffsetX, tc.Y+t.cfg.OffsetY
+			t.lastX, t.lastY = tc.X, tc.Y
+		}
+	}
+	if free >= 2 {
+		s.Held |= Dash
+	}
+	return s
+}
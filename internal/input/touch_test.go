@@ -0,0 +1,86 @@
+package input
+
+import "testing"
+
+// fakeScreen is a scriptable TouchScreen.
+type fakeScreen struct {
+	touches []Touch
+}
+
+func (f *fakeScreen) Touches() []Touch { return f.touches }
+
+func TestTouchDragWithOffset(t *testing.T) {
+	screen := &fakeScreen{}
+	src := NewTouchSource(screen, TouchConfig{OffsetX: 5, OffsetY: -60})
+
+	if s := src.Poll(); s.Target || src.Dragging() || src.Used() {
+		t.Fatalf("expected no target without a finger, got %+v", s)
+	}
+
+	screen.touches = []Touch{{ID: 1, X: 100, Y: 200}}
+	s := src.Poll()
+	if !s.Target || s.X != 105 || s.Y != 140 || !src.Dragging() || !src.Used() {
+		t.Fatalf("expected a target offset from the finger, got %+v", s)
+	}
+	if !s.Pointer || s.PointerX != 100 || s.PointerY != 200 || !s.Held.Has(Click) {
+		t.Fatalf("expected the finger itself to point and click, got %+v", s)
+	}
+
+	// A finger held still stops reporting, like a still mouse.
+	if s := src.Poll(); s.Target {
+		t.Fatalf("expected no target from a still finger, got %+v", s)
+	}
+
+	screen.touches = nil
+	src.Poll()
+	if src.Dragging() {
+		t.Fatalf("expected lifting the finger to end the drag")
+	}
+}
+
+func TestTouchTwoFingerDash(t *testing.T) {
+	screen := &fakeScreen{touches: []Touch{{ID: 1, X: 100, Y: 200}}}
+	r := NewReader(NewTouchSource(screen, DefaultTouchConfig))
+	r.Next()
+
+	screen.touches = append(screen.touches, Touch{ID: 2, X: 300, Y: 300})
+	if f := r.Next(); !f.Pressed.Has(Dash) {
+		t.Fatalf("expected a second finger to dash, got %+v", f)
+	}
+	// The first finger keeps steering.
+	screen.touches[0].X = 120
+	if f := r.Next(); !f.Target || f.X != 120 {
+		t.Fatalf("expected the first finger to keep dragging, got %+v", f)
+	}
+}
+
+func TestTouchButtons(t *testing.T) {
+	screen := &fakeScreen{}
+	src := NewTouchSource(screen, DefaultTouchConfig)
+	src.SetButtons([]TouchButton{
+		{Action: Pause, X: 600, Y: 30, R: 20},
+		{Action: Dash, X: 580, Y: 420, R: 40},
+	})
+	r := NewReader(src)
+
+	screen.touches = []Touch{{ID: 1, X: 605, Y: 25}}
+	if f := r.Next(); !f.Pressed.Has(Pause) || f.Target || src.Dragging() {
+		t.Fatalf("expected a tap on pause to only pause, got %+v", f)
+	}
+
+	// Dragging with one finger while tapping dash with another.
+	screen.touches = []Touch{{ID: 2, X: 100, Y: 100}}
+	r.Next()
+	screen.touches = append(screen.touches, Touch{ID: 3, X: 570, Y: 430})
+	f := r.Next()
+	if !f.Pressed.Has(Dash) {
+		t.Fatalf("expected the dash button to dash, got %+v", f)
+	}
+
+	// A button finger that slides off still holds its button and never
+	// steers.
+	screen.touches[1].X, screen.touches[1].Y = 300, 300
+	if f := r.Next(); !f.Held.Has(Dash) || f.Target {
+		t.Fatalf("expected the dash finger to keep holding dash, got %+v", f)
+	}
+}
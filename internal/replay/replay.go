@@ -0,0 +1,473 @@
+// Package replay records runs as the seed and tuning plus the input of
+// every tick and reads them back. Because sim is deterministic, that is all
+// it takes to reproduce a run exactly.
+//
+// A replay file is the 4-byte magic "SQRP", a little-endian uint16 format
+// version, then a gzip stream holding the header fields, the mode, the
+// tuning as JSON, and the inputs, run-length encoded since consecutive
+// ticks are often identical.
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+const (
+	magic = "SQRP"
+
+	// Version is the file format version written by Encode. Files of any
+	// other version are refused.
+	Version = 1
+
+	// Ext is the conventional replay file extension.
+	Ext = ".sqr"
+)
+
+var ErrFormat = errors.New("replay: not a replay file")
+
+// Replay is a recorded run.
+type Replay struct {
+	// Rules is the sim.RulesVersion the run was played under.
+	Rules  int
+	Seed   int64
+	Mode   sim.Mode
+	Width  float64
+	Height float64
+	Tuning sim.Tuning
+
+	// ViewWidth and ViewHeight are the camera's view; zero for the whole
+	// arena.
+	ViewWidth  float64
+	ViewHeight float64
+
+	// Changes lists tuning swapped in during the run, in tick order.
+	Changes []TuningChange
+
+	// Inputs holds the input of every tick, in order.
+	Inputs []sim.Input
+
+	// Result is the outcome claimed by whoever wrote the file.
+	Result sim.Result
+
+	// next is the first of Changes that Step has not applied yet.
+	next int
+}
+
+// TuningChange is a tuning applied right before tick Tick was stepped.
+type TuningChange struct {
+	Tick   int
+	Tuning sim.Tuning
+}
+
+// Options returns the world options the run was started with.
+func (r *Replay) Options() sim.Options {
+	return sim.Options{
+		Seed:       r.Seed,
+		Mode:       r.Mode,
+		Width:      r.Width,
+		Height:     r.Height,
+		ViewWidth:  r.ViewWidth,
+		ViewHeight: r.ViewHeight,
+		Tuning:     &r.Tuning,
+	}
+}
+
+// Step advances w through the given tick of the recording, applying any
+// tuning change due first. Ticks are stepped in order, starting over from
+// tick 0 with a new world.
+func (r *Replay) Step(w *sim.World, tick int) {
+	if tick == 0 {
+		r.next = 0
+	}
+	for r.next < len(r.Changes) && r.Changes[r.next].Tick <= tick {
+		w.SetTuning(r.Changes[r.next].Tuning)
+		r.next++
+	}
+	w.Step(r.Inputs[tick])
+}
+
+// Recorder captures a run as it is played.
+type Recorder struct {
+	r Replay
+}
+
+func NewRecorder(opts sim.Options) *Recorder {
+	t := sim.DefaultTuning()
+	if opts.Tuning != nil {
+		t = *opts.Tuning
+	}
+	mode := opts.Mode
+	if mode == "" {
+		mode = sim.ModeClassic
+	}
+	return &Recorder{r: Replay{
+		Rules:  sim.RulesVersion,
+		Seed:   opts.Seed,
+		Mode:   mode,
+		Width:  opts.Width,
+		Height: opts.Height,
+		Tuning: t,
+
+		ViewWidth:  opts.ViewWidth,
+		ViewHeight: opts.ViewHeight,
+	}}
+}
+
+// ChangeTuning notes that t applies from the next recorded tick on.
+func (rec *Recorder) ChangeTuning(t sim.Tuning) {
+	rec.r.Changes = append(rec.r.Changes, TuningChange{Tick: len(rec.r.Inputs), Tuning: t})
+}
+
+// Record appends the input of one tick.
+func (rec *Recorder) Record(in sim.Input) {
+	rec.r.Inputs = append(rec.r.Inputs, in)
+}
+
+// Finish stamps the run's result and returns the replay.
+func (rec *Recorder) Finish(res sim.Result) *Replay {
+	rec.r.Result = res
+	return &rec.r
+}
+
+// Input flag bits, one byte per run of identical inputs.
+const (
+	flagTarget = 1 << iota
+	flagMove
+	flagDash
+)
+
+func Encode(w io.Writer, r *Replay) error {
+	if _, err := io.WriteString(w, magic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(Version)); err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	e := encoder{w: bw}
+
+	e.uvarint(uint64(r.Rules))
+	e.varint(r.Seed)
+	e.float(r.Width)
+	e.float(r.Height)
+	e.string(string(r.Mode))
+	e.float(r.ViewWidth)
+	e.float(r.ViewHeight)
+
+	e.tuning(r.Tuning)
+	e.uvarint(uint64(len(r.Changes)))
+	for _, c := range r.Changes {
+		e.uvarint(uint64(c.Tick))
+		e.tuning(c.Tuning)
+	}
+
+	e.uvarint(uint64(r.Result.Score))
+	e.uvarint(uint64(r.Result.Ticks))
+	e.float(r.Result.MaxSize)
+	e.uvarint(uint64(r.Result.Cause))
+
+	e.uvarint(uint64(len(r.Inputs)))
+	for i := 0; i < len(r.Inputs); {
+		in := r.Inputs[i]
+		n := 1
+		for i+n < len(r.Inputs) && r.Inputs[i+n] == in {
+			n++
+		}
+		e.uvarint(uint64(n))
+		e.input(in)
+		i += n
+	}
+
+	if e.err != nil {
+		return e.err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func Decode(rd io.Reader) (*Replay, error) {
+	var head [len(magic)]byte
+	if _, err := io.ReadFull(rd, head[:]); err != nil || string(head[:]) != magic {
+		return nil, ErrFormat
+	}
+	var version uint16
+	if err := binary.Read(rd, binary.LittleEndian, &version); err != nil {
+		return nil, ErrFormat
+	}
+	if version != Version {
+		return nil, fmt.Errorf("replay: unsupported format version %d (want %d)", version, Version)
+	}
+
+	zr, err := gzip.NewReader(rd)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	defer zr.Close()
+	d := decoder{r: bufio.NewReader(zr)}
+
+	r := &Replay{}
+	r.Rules = int(d.uvarint())
+	r.Seed = d.varint()
+	r.Width = d.float()
+	r.Height = d.float()
+
+	mode, err := sim.ParseMode(d.string(maxModeLen))
+	if d.err == nil && err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	r.Mode = mode
+	r.ViewWidth = d.float()
+	r.ViewHeight = d.float()
+
+	r.Tuning = d.tuning()
+	n := d.uvarint()
+	if d.err == nil && n > maxChanges {
+		return nil, fmt.Errorf("replay: %d tuning changes is more than the %d supported", n, maxChanges)
+	}
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		tick := int(d.uvarint())
+		if len(r.Changes) > 0 && tick < r.Changes[len(r.Changes)-1].Tick {
+			return nil, errors.New("replay: tuning changes out of order")
+		}
+		r.Changes = append(r.Changes, TuningChange{Tick: tick, Tuning: d.tuning()})
+	}
+
+	r.Result.Score = int(d.uvarint())
+	r.Result.Ticks = int(d.uvarint())
+	r.Result.MaxSize = d.float()
+	r.Result.Cause = sim.Cause(d.uvarint())
+
+	total := d.uvarint()
+	if d.err == nil && total > maxTicks {
+		return nil, fmt.Errorf("replay: %d ticks is more than the %d supported", total, maxTicks)
+	}
+	r.Inputs = make([]sim.Input, 0, total)
+	for d.err == nil && uint64(len(r.Inputs)) < total {
+		n := d.uvarint()
+		in := d.input()
+		if n == 0 || uint64(len(r.Inputs))+n > total {
+			return nil, errors.New("replay: corrupt input stream")
+		}
+		for ; n > 0; n-- {
+			r.Inputs = append(r.Inputs, in)
+		}
+	}
+	if errors.Is(d.err, io.EOF) || errors.Is(d.err, io.ErrUnexpectedEOF) {
+		return nil, errors.New("replay: truncated file")
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("replay: %w", d.err)
+	}
+	return r, nil
+}
+
+// Limits on what Decode will allocate for: 24 hours of play, and tunings
+// far bigger and more frequent than any real ones.
+const (
+	maxTicks     = 24 * 60 * 60 * sim.TickRate
+	maxTuningLen = 64 << 10
+	maxModeLen   = 64
+	maxChanges   = 10000
+)
+
+// Load reads the replay at path.
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(bufio.NewReader(f))
+}
+
+// Save writes r to path, replacing any existing file only once the new one
+// is completely written.
+func Save(path string, r *Replay) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".replay-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := Encode(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// DefaultDir is where the game keeps replays.
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "squares", "replays"), nil
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (e *encoder) write(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *encoder) uvarint(v uint64) { e.write(binary.AppendUvarint(e.buf[:0], v)) }
+func (e *encoder) varint(v int64)   { e.write(binary.AppendVarint(e.buf[:0], v)) }
+
+func (e *encoder) float(v float64) {
+	e.write(binary.LittleEndian.AppendUint64(e.buf[:0], math.Float64bits(v)))
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.write([]byte(s))
+}
+
+func (e *encoder) tuning(t sim.Tuning) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		if e.err == nil {
+			e.err = err
+		}
+		return
+	}
+	e.uvarint(uint64(len(b)))
+	e.write(b)
+}
+
+func (e *encoder) input(in sim.Input) {
+	var flags byte
+	if in.Target {
+		flags |= flagTarget
+	}
+	if in.MoveX != 0 || in.MoveY != 0 {
+		flags |= flagMove
+	}
+	if in.Dash {
+		flags |= flagDash
+	}
+	e.write([]byte{flags})
+
+	// Positions are stored even without a target: they are part of the
+	// input the sim saw, and a replay must reproduce it bit for bit.
+	e.float(in.X)
+	e.float(in.Y)
+	if flags&flagMove != 0 {
+		e.float(in.MoveX)
+		e.float(in.MoveY)
+	}
+}
+
+type decoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	var v uint64
+	v, d.err = binary.ReadUvarint(d.r)
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	var v int64
+	v, d.err = binary.ReadVarint(d.r)
+	return v
+}
+
+func (d *decoder) float() float64 {
+	if d.err != nil {
+		return 0
+	}
+	var b [8]byte
+	_, d.err = io.ReadFull(d.r, b[:])
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return b
+}
+
+func (d *decoder) string(limit int) string {
+	n := d.uvarint()
+	if d.err == nil && n > uint64(limit) {
+		d.err = fmt.Errorf("%d byte string is more than the %d supported", n, limit)
+	}
+	return string(d.bytes(int(n)))
+}
+
+func (d *decoder) tuning() sim.Tuning {
+	n := d.uvarint()
+	if d.err == nil && n > maxTuningLen {
+		d.err = fmt.Errorf("%d bytes of tuning is more than the %d supported", n, maxTuningLen)
+	}
+	buf := d.bytes(int(n))
+	if d.err != nil {
+		return sim.Tuning{}
+	}
+	// Knobs added after the replay was written are missing from it; the
+	// defaults keep new knobs behaving as the game did before they existed.
+	t, err := sim.ParseTuning(buf, sim.DefaultTuning())
+	if err != nil {
+		d.err = err
+	}
+	return t
+}
+
+func (d *decoder) input() sim.Input {
+	if d.err != nil {
+		return sim.Input{}
+	}
+	var flags byte
+	flags, d.err = d.r.ReadByte()
+
+	in := sim.Input{
+		Target: flags&flagTarget != 0,
+		Dash:   flags&flagDash != 0,
+	}
+	in.X = d.float()
+	in.Y = d.float()
+	if flags&flagMove != 0 {
+		in.MoveX = d.float()
+		in.MoveY = d.float()
+	}
+	return in
+}
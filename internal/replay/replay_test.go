@@ -0,0 +1,307 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+func testOptions() sim.Options {
+	return sim.Options{Seed: 99, Width: 640, Height: 480}
+}
+
+// record plays a scripted run for at most ticks ticks.
+func record(ticks int) *Replay {
+	opts := testOptions()
+	w := sim.New(opts)
+	rec := NewRecorder(opts)
+	for i := 0; i < ticks && !w.Over(); i++ {
+		in := sim.Input{Dash: i%120 == 0}
+		if i%200 < 100 {
+			a := float64(i) * 0.03
+			in.Target = true
+			in.X = 320 + 200*math.Cos(a)
+			in.Y = 240 + 150*math.Sin(a)
+		} else {
+			in.MoveX = -1
+			in.MoveY = 0.5
+		}
+		w.Step(in)
+		rec.Record(in)
+	}
+	return rec.Finish(w.Result())
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	r := record(2000)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, r); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if got.Rules != r.Rules || got.Seed != r.Seed || got.Width != r.Width || got.Height != r.Height {
+		t.Fatalf("header mismatch: got %+v", got)
+	}
+	if got.Result != r.Result {
+		t.Fatalf("result mismatch: got %+v want %+v", got.Result, r.Result)
+	}
+	if len(got.Inputs) != len(r.Inputs) {
+		t.Fatalf("input count mismatch: got %d want %d", len(got.Inputs), len(r.Inputs))
+	}
+	for i := range r.Inputs {
+		if got.Inputs[i] != r.Inputs[i] {
+			t.Fatalf("input %d mismatch: got %+v want %+v", i, got.Inputs[i], r.Inputs[i])
+		}
+	}
+}
+
+func TestReplayReproducesRun(t *testing.T) {
+	r := record(5000)
+
+	w := sim.New(r.Options())
+	for _, in := range r.Inputs {
+		w.Step(in)
+	}
+	if w.Result() != r.Result {
+		t.Fatalf("replayed result %+v, recorded %+v", w.Result(), r.Result)
+	}
+}
+
+func TestDecodeRejectsGarbage(t *testing.T) {
+	if _, err := Decode(bytes.NewReader([]byte("not a replay"))); !errors.Is(err, ErrFormat) {
+		t.Fatalf("expected ErrFormat, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, record(300)); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	truncated := buf.Bytes()[:buf.Len()/2]
+	if _, err := Decode(bytes.NewReader(truncated)); err == nil {
+		t.Fatalf("expected an error for a truncated file")
+	}
+
+	other := bytes.Clone(buf.Bytes())
+	other[len(magic)]++
+	if _, err := Decode(bytes.NewReader(other)); err == nil {
+		t.Fatalf("expected an error for another format version")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	r := record(600)
+	path := filepath.Join(t.TempDir(), "nested", "run"+Ext)
+
+	if err := Save(path, r); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.Result != r.Result || len(got.Inputs) != len(r.Inputs) {
+		t.Fatalf("loaded replay differs: %+v", got.Result)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	r := record(5000)
+	if _, err := Verify(r); err != nil {
+		t.Fatalf("expected untouched replay to verify: %v", err)
+	}
+
+	edited := *r
+	edited.Result.Score += 10
+	got, err := Verify(&edited)
+	if err == nil {
+		t.Fatalf("expected edited score to fail verification")
+	}
+	if got != r.Result {
+		t.Fatalf("expected simulated result %+v, got %+v", r.Result, got)
+	}
+
+	edited = *r
+	edited.Rules++
+	if _, err := Verify(&edited); err == nil {
+		t.Fatalf("expected a rules mismatch to fail verification")
+	}
+}
+
+func TestStandardRejectsEditedTuning(t *testing.T) {
+	r := record(600)
+	if err := Standard(r); err != nil {
+		t.Fatalf("expected a run on the built-in tuning to be standard: %v", err)
+	}
+
+	// No hazards, and the claim re-recorded to match: it re-simulates
+	// fine, but is not a standard run.
+	easy := sim.DefaultTuning()
+	easy.HazardP = sim.Curve{}
+	opts := testOptions()
+	opts.Tuning = &easy
+	w := sim.New(opts)
+	edited := *r
+	edited.Tuning = easy
+	for i := range edited.Inputs {
+		if w.Over() {
+			edited.Inputs = edited.Inputs[:i]
+			break
+		}
+		w.Step(edited.Inputs[i])
+	}
+	edited.Result = w.Result()
+	if _, err := Verify(&edited); err != nil {
+		t.Fatalf("expected the edited run to re-simulate to its claim: %v", err)
+	}
+	if err := Standard(&edited); !errors.Is(err, ErrCustomTuning) {
+		t.Fatalf("expected edited tuning to be refused, got %v", err)
+	}
+}
+
+func TestTuningRoundTrips(t *testing.T) {
+	tuning := sim.DefaultTuning()
+	tuning.GrowthScale = 0.125
+	tuning.HazardP.Max = 0.4
+
+	opts := testOptions()
+	opts.Tuning = &tuning
+	rec := NewRecorder(opts)
+	rec.Record(sim.Input{})
+	r := rec.Finish(sim.Result{})
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, r); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Tuning != tuning {
+		t.Fatalf("tuning did not round-trip: got %+v", got.Tuning)
+	}
+}
+
+func TestTuningChangesReplay(t *testing.T) {
+	opts := testOptions()
+	w := sim.New(opts)
+	rec := NewRecorder(opts)
+
+	harder := sim.DefaultTuning()
+	harder.SpawnInterval = sim.Curve{Base: 0.1, Min: 0.1, Max: 0.1}
+	harder.HazardP = sim.Curve{Base: 0.5, Min: 0.5, Max: 0.5}
+
+	for i := 0; i < 3000 && !w.Over(); i++ {
+		if i == 300 {
+			w.SetTuning(harder)
+			rec.ChangeTuning(harder)
+		}
+		in := sim.Input{Target: true, X: 320 + float64(i%100), Y: 240}
+		w.Step(in)
+		rec.Record(in)
+	}
+	r := rec.Finish(w.Result())
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, r); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got.Changes) != 1 || got.Changes[0].Tick != 300 || got.Changes[0].Tuning != harder {
+		t.Fatalf("tuning change did not round-trip: %+v", got.Changes)
+	}
+	if _, err := Verify(got); err != nil {
+		t.Fatalf("expected run with a live tuning change to verify: %v", err)
+	}
+
+	if err := Standard(got); !errors.Is(err, ErrCustomTuning) {
+		t.Fatalf("expected a live tuning change to make the run non-standard, got %v", err)
+	}
+
+	// Dropping the change must make the run diverge.
+	got.Changes = nil
+	if _, err := Verify(got); err == nil {
+		t.Fatalf("expected verification to fail without the tuning change")
+	}
+}
+
+func TestModeReplays(t *testing.T) {
+	opts := testOptions()
+	opts.Mode = sim.ModeInertia
+	w := sim.New(opts)
+	rec := NewRecorder(opts)
+	for i := 0; i < 1500 && !w.Over(); i++ {
+		in := sim.Input{Target: i%30 == 0, X: 320 + 150*math.Cos(float64(i)*0.05), Y: 240}
+		w.Step(in)
+		rec.Record(in)
+	}
+	r := rec.Finish(w.Result())
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, r); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Mode != sim.ModeInertia {
+		t.Fatalf("mode did not round-trip: got %q", got.Mode)
+	}
+	if _, err := Verify(got); err != nil {
+		t.Fatalf("expected inertia run to verify: %v", err)
+	}
+
+	// The same inputs play out differently in another mode.
+	got.Mode = sim.ModeClassic
+	if _, err := Verify(got); err == nil {
+		t.Fatalf("expected verification to fail under the wrong mode")
+	}
+}
+
+func TestViewReplays(t *testing.T) {
+	opts := testOptions()
+	opts.Width, opts.Height = 1920, 1440
+	opts.ViewWidth, opts.ViewHeight = 640, 480
+	w := sim.New(opts)
+	rec := NewRecorder(opts)
+	for i := 0; i < 1500 && !w.Over(); i++ {
+		in := sim.Input{Target: true, X: 960 + 600*math.Cos(float64(i)*0.01), Y: 720 + 500*math.Sin(float64(i)*0.01)}
+		w.Step(in)
+		rec.Record(in)
+	}
+	r := rec.Finish(w.Result())
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, r); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.ViewWidth != 640 || got.ViewHeight != 480 {
+		t.Fatalf("view did not round-trip: got %vx%v", got.ViewWidth, got.ViewHeight)
+	}
+	if _, err := Verify(got); err != nil {
+		t.Fatalf("expected scrolling run to verify: %v", err)
+	}
+
+	// Spawns follow the camera, so without it the run plays out differently.
+	got.ViewWidth, got.ViewHeight = 0, 0
+	if _, err := Verify(got); err == nil {
+		t.Fatalf("expected verification to fail without the view")
+	}
+}
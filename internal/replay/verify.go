@@ -0,0 +1,56 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+// Simulate re-runs r headlessly and returns the result it actually
+// produces, which may differ from the one r claims.
+func Simulate(r *Replay) (sim.Result, error) {
+	if r.Rules != sim.RulesVersion {
+		return sim.Result{}, fmt.Errorf("replay uses rules v%d; this build simulates v%d", r.Rules, sim.RulesVersion)
+	}
+
+	w := sim.New(r.Options())
+	for i := range r.Inputs {
+		if w.Over() {
+			return w.Result(), fmt.Errorf("replay has %d inputs after the run ended on tick %d", len(r.Inputs)-i, i)
+		}
+		r.Step(w, i)
+	}
+	return w.Result(), nil
+}
+
+// Verify re-simulates r and reports an error unless the result matches
+// the one it claims exactly.
+func Verify(r *Replay) (sim.Result, error) {
+	got, err := Simulate(r)
+	if err != nil {
+		return got, err
+	}
+	if got != r.Result {
+		return got, fmt.Errorf("claimed result %+v does not match simulated %+v", r.Result, got)
+	}
+	return got, nil
+}
+
+// ErrCustomTuning is reported by Standard for a run that was not played
+// under the built-in tuning throughout. The tuning is stored in the file like
+// everything else, so a run that re-simulates to its claimed result may
+// still have been played, or edited, to be easier.
+var ErrCustomTuning = errors.New("replay: custom tuning")
+
+// Standard reports an error wrapping ErrCustomTuning unless r was played
+// under the built-in tuning, with no live tuning changes.
+func Standard(r *Replay) error {
+	if r.Tuning != sim.DefaultTuning() {
+		return fmt.Errorf("%w: the run was not played with the built-in tuning", ErrCustomTuning)
+	}
+	if len(r.Changes) > 0 {
+		return fmt.Errorf("%w: tuning was reloaded %d times during the run", ErrCustomTuning, len(r.Changes))
+	}
+	return nil
+}
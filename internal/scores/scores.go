@@ -0,0 +1,154 @@
+// Package scores keeps the local high-score table: the best runs played on
+// this machine, in a small JSON file in the user's config directory.
+package scores
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+	"github.com/jdefrancesco/squares/internal/store"
+)
+
+const (
+	// Version is the file format version written by Save. Files from a
+	// newer version are refused rather than overwritten.
+	Version = 1
+
+	// DefaultLimit is how many runs the table keeps.
+	DefaultLimit = 10
+)
+
+// ErrCorrupt is reported when the file on disk cannot be read as a table.
+var ErrCorrupt = errors.New("scores: corrupt file")
+
+// Entry is one run in the table.
+type Entry struct {
+	Score int `json:"score"`
+	// Survival is how long the run lasted, in seconds.
+	Survival float64   `json:"survival"`
+	MaxSize  float64   `json:"max_size"`
+	Seed     int64     `json:"seed"`
+	Date     time.Time `json:"date"`
+	Mode     sim.Mode  `json:"mode"`
+}
+
+// NewEntry describes a finished run.
+func NewEntry(res sim.Result, seed int64, mode sim.Mode, date time.Time) Entry {
+	return Entry{
+		Score:    res.Score,
+		Survival: float64(res.Ticks) / sim.TickRate,
+		MaxSize:  res.MaxSize,
+		Seed:     seed,
+		Date:     date,
+		Mode:     mode,
+	}
+}
+
+// beats reports whether e ranks above o: a higher score, then a longer
+// run. On a full tie the older entry keeps its place.
+func (e Entry) beats(o Entry) bool {
+	if e.Score != o.Score {
+		return e.Score > o.Score
+	}
+	return e.Survival > o.Survival
+}
+
+func (e Entry) valid() bool {
+	return e.Score >= 0 &&
+		e.Survival >= 0 && !math.IsInf(e.Survival, 0) &&
+		e.MaxSize >= 0 && !math.IsInf(e.MaxSize, 0)
+}
+
+type file struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Table is a high-score table backed by a file. Every change is written
+// straight back to it.
+type Table struct {
+	path    string
+	limit   int
+	entries []Entry
+}
+
+// Open reads the table at path, keeping at most limit entries. A missing
+// file is an empty table. A file that cannot be read as one is moved
+// aside to path+".corrupt" and Open returns an empty table along with an
+// error wrapping ErrCorrupt, so a bad file costs the old scores but never
+// the new ones. Entries with impossible values are dropped.
+func Open(path string, limit int) (*Table, error) {
+	t := &Table{path: path, limit: limit}
+	var f file
+	if err := t.file().Load(&f); err != nil {
+		if errors.Is(err, ErrCorrupt) {
+			return t, err
+		}
+		return nil, err
+	}
+
+	for _, e := range f.Entries {
+		if e.valid() {
+			t.entries = append(t.entries, e)
+		}
+	}
+	slices.SortStableFunc(t.entries, func(a, b Entry) int {
+		switch {
+		case a.beats(b):
+			return -1
+		case b.beats(a):
+			return 1
+		}
+		return 0
+	})
+	if len(t.entries) > limit {
+		t.entries = t.entries[:limit]
+	}
+	return t, nil
+}
+
+// DefaultPath is where the game keeps its table.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "squares", "scores.json"), nil
+}
+
+// Entries returns the table, best first. It is owned by the table.
+func (t *Table) Entries() []Entry {
+	return t.entries
+}
+
+// Add puts e in the table and saves it, returning e's index in Entries, or
+// -1 if it did not make the cut and nothing changed.
+func (t *Table) Add(e Entry) (int, error) {
+	i := 0
+	for i < len(t.entries) && !e.beats(t.entries[i]) {
+		i++
+	}
+	if i >= t.limit || !e.valid() {
+		return -1, nil
+	}
+	t.entries = slices.Insert(t.entries, i, e)
+	if len(t.entries) > t.limit {
+		t.entries = t.entries[:t.limit]
+	}
+	return i, t.save()
+}
+
+// save writes the table through a temporary file renamed into place, so
+// a crash mid-write leaves the previous table intact.
+func (t *Table) save() error {
+	return t.file().Save(file{Version: Version, Entries: t.entries})
+}
+
+func (t *Table) file() store.File {
+	return store.File{Path: t.path, Name: "scores", Version: Version, Corrupt: ErrCorrupt}
+}
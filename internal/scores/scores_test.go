@@ -0,0 +1,117 @@
+package scores
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jdefrancesco/squares/internal/sim"
+)
+
+func entry(score int, survival float64) Entry {
+	return Entry{
+		Score:    score,
+		Survival: survival,
+		MaxSize:  40,
+		Seed:     int64(score),
+		Date:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Mode:     sim.ModeClassic,
+	}
+}
+
+func TestAddRanksAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "scores.json")
+	tab, err := Open(path, 3)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	for _, c := range []struct {
+		e    Entry
+		want int
+	}{
+		{entry(10, 30), 0},
+		{entry(20, 30), 0},
+		{entry(10, 40), 1}, // same score, lasted longer
+		{entry(10, 40), 2}, // full tie: the older one stays ahead
+		{entry(5, 99), -1},
+	} {
+		got, err := tab.Add(c.e)
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+		if got != c.want {
+			t.Fatalf("adding %+v ranked %d, want %d", c.e, got, c.want)
+		}
+	}
+
+	again, err := Open(path, 3)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	want := []Entry{entry(20, 30), entry(10, 40), entry(10, 40)}
+	got := again.Entries()
+	if len(got) != len(want) {
+		t.Fatalf("reopened table has %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Date.Equal(want[i].Date) {
+			t.Fatalf("entry %d date %v, want %v", i, got[i].Date, want[i].Date)
+		}
+		got[i].Date = want[i].Date
+		if got[i] != want[i] {
+			t.Fatalf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOpenMovesCorruptFileAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1, "entries": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tab, err := Open(path, DefaultLimit)
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	if tab == nil || len(tab.Entries()) != 0 {
+		t.Fatalf("expected an empty table to play on with")
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Fatalf("corrupt file not kept: %v", err)
+	}
+	if _, err := tab.Add(entry(3, 10)); err != nil {
+		t.Fatalf("add after corruption: %v", err)
+	}
+}
+
+func TestOpenDropsBadEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.json")
+	data := `{"version": 1, "entries": [{"score": -4}, {"score": 7, "survival": 12}, {"score": 9, "survival": -1}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tab, err := Open(path, DefaultLimit)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if got := tab.Entries(); len(got) != 1 || got[0].Score != 7 {
+		t.Fatalf("expected only the valid entry, got %+v", got)
+	}
+}
+
+func TestOpenRefusesNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "entries": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, DefaultLimit); err == nil {
+		t.Fatalf("expected an error for a newer format")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("newer file should be left alone: %v", err)
+	}
+}
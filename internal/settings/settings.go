@@ -0,0 +1,214 @@
+// Package settings loads and saves the player's preferences: the window,
+// the controls, the look of the game and its HUD. They live in a small
+// JSON file in the user's config directory next to the high scores.
+package settings
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"github.com/jdefrancesco/squares/internal/input"
+	"github.com/jdefrancesco/squares/internal/store"
+)
+
+// Version is the file format version written by Save. Files from a newer
+// version are refused rather than overwritten.
+const Version = 1
+
+// ErrCorrupt is reported when the file on disk cannot be read as settings.
+var ErrCorrupt = errors.New("settings: corrupt file")
+
+// Scheme is what steers the player. Whatever is not steering still works
+// the menus.
+type Scheme string
+
+const (
+	// SchemeAuto steers with anything.
+	SchemeAuto Scheme = "auto"
+	// SchemeMouse steers with the mouse or a finger only.
+	SchemeMouse Scheme = "mouse"
+	// SchemeKeys steers with the keyboard or a gamepad only, so a mouse
+	// that gets knocked does not yank the player.
+	SchemeKeys Scheme = "keys"
+)
+
+// Schemes lists every control scheme.
+func Schemes() []Scheme {
+	return []Scheme{SchemeAuto, SchemeMouse, SchemeKeys}
+}
+
+// Theme is the game's color scheme.
+type Theme string
+
+const (
+	ThemeLight Theme = "light"
+	ThemeDark  Theme = "dark"
+)
+
+// Themes lists every theme.
+func Themes() []Theme {
+	return []Theme{ThemeLight, ThemeDark}
+}
+
+// The choices offered on the settings screen. Files may hold other
+// values within range.
+var (
+	WindowScales = []float64{1, 1.5, 2, 3}
+	// FPSCaps are frame rate limits; 0 means no limit beyond vsync.
+	FPSCaps       = []int{0, 30, 60, 120, 144, 240}
+	Sensitivities = []float64{0.25, 0.5, 0.75, 1, 1.25, 1.5, 2, 3, 4}
+)
+
+// Limits on sensitivity, in multiples of the default.
+const (
+	MinSensitivity = 0.25
+	MaxSensitivity = 4
+)
+
+// Settings is everything the player can set.
+type Settings struct {
+	Fullscreen bool `json:"fullscreen"`
+	// WindowScale multiplies the starting window size when not in
+	// fullscreen.
+	WindowScale float64 `json:"window_scale"`
+	VSync       bool    `json:"vsync"`
+	FPSCap      int     `json:"fps_cap"`
+
+	Controls Scheme `json:"controls"`
+	// How far the player moves for a given mouse or stick movement, in
+	// multiples of the default.
+	MouseSensitivity float64 `json:"mouse_sensitivity"`
+	StickSensitivity float64 `json:"stick_sensitivity"`
+	// Bindings are the keys and buttons for each action. Actions the file
+	// leaves out keep their defaults.
+	Bindings input.Bindings `json:"bindings"`
+
+	// Volumes run from 0 to 1. The game has no sound yet; they are kept
+	// so the file does not change when it does.
+	MasterVolume  float64 `json:"master_volume"`
+	MusicVolume   float64 `json:"music_volume"`
+	EffectsVolume float64 `json:"effects_volume"`
+
+	Theme Theme `json:"theme"`
+	// Colorblind swaps the green and teal the game tells things apart by
+	// for colors that stay distinct with any color vision.
+	Colorblind bool `json:"colorblind"`
+
+	ShowHUD     bool `json:"show_hud"`
+	ShowMinimap bool `json:"show_minimap"`
+	ShowFPS     bool `json:"show_fps"`
+
+	// Language is a language tag such as "en". The game falls back to
+	// English for languages it does not have.
+	Language string `json:"language"`
+}
+
+// Default is how the game plays out of the box.
+func Default() Settings {
+	return Settings{
+		WindowScale:      1,
+		VSync:            true,
+		Controls:         SchemeAuto,
+		MouseSensitivity: 1,
+		StickSensitivity: 1,
+		Bindings:         input.DefaultBindings(),
+		MasterVolume:     1,
+		MusicVolume:      0.7,
+		EffectsVolume:    1,
+		Theme:            ThemeLight,
+		ShowHUD:          true,
+		ShowMinimap:      true,
+		Language:         "en",
+	}
+}
+
+// Equal reports whether s and o are the same settings. Settings hold
+// bindings, so they cannot be compared with ==.
+func (s Settings) Equal(o Settings) bool {
+	return reflect.DeepEqual(s, o)
+}
+
+type file struct {
+	Version int `json:"version"`
+	Settings
+}
+
+// Load reads the settings at path. A missing file gives the defaults, as
+// does any setting missing from the file or out of range. A file that
+// cannot be read as settings is moved aside to path+".corrupt" and Load
+// returns the defaults along with an error wrapping ErrCorrupt. A file
+// from a newer version is an error and is left alone.
+func Load(path string) (Settings, error) {
+	f := file{Settings: Default()}
+	if err := storeFile(path).Load(&f); err != nil {
+		return Default(), err
+	}
+	return f.Settings.clean(), nil
+}
+
+// clean puts every setting that is out of range back to its default, and
+// the bindings of actions whose controls clash.
+func (s Settings) clean() Settings {
+	d := Default()
+	if !(s.WindowScale >= 0.5 && s.WindowScale <= 4) {
+		s.WindowScale = d.WindowScale
+	}
+	if s.FPSCap < 0 || s.FPSCap > 1000 {
+		s.FPSCap = d.FPSCap
+	}
+	if !slices.Contains(Schemes(), s.Controls) {
+		s.Controls = d.Controls
+	}
+	s.MouseSensitivity = inRange(s.MouseSensitivity, MinSensitivity, MaxSensitivity, d.MouseSensitivity)
+	s.StickSensitivity = inRange(s.StickSensitivity, MinSensitivity, MaxSensitivity, d.StickSensitivity)
+	s.MasterVolume = inRange(s.MasterVolume, 0, 1, d.MasterVolume)
+	s.MusicVolume = inRange(s.MusicVolume, 0, 1, d.MusicVolume)
+	s.EffectsVolume = inRange(s.EffectsVolume, 0, 1, d.EffectsVolume)
+	if !slices.Contains(Themes(), s.Theme) {
+		s.Theme = d.Theme
+	}
+	if s.Language == "" {
+		s.Language = d.Language
+	}
+	// Put clashing actions back to their defaults, which never clash, until
+	// nothing clashes with them either.
+	for clash := s.Bindings.Clashing(); clash != 0; clash = s.Bindings.Clashing() {
+		for _, a := range input.Actions() {
+			if clash.Has(a) {
+				s.Bindings = s.Bindings.Reset(a)
+			}
+		}
+	}
+	return s
+}
+
+// inRange is v if it lies in [lo, hi], otherwise def.
+func inRange(v, lo, hi, def float64) float64 {
+	if math.IsNaN(v) || v < lo || v > hi {
+		return def
+	}
+	return v
+}
+
+// DefaultPath is where the game keeps its settings.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "squares", "settings.json"), nil
+}
+
+// Save writes s to path through a temporary file renamed into place, so a
+// crash mid-write leaves the previous settings intact.
+func Save(path string, s Settings) error {
+	return storeFile(path).Save(file{Version: Version, Settings: s})
+}
+
+func storeFile(path string) store.File {
+	return store.File{Path: path, Name: "settings", Version: Version, Corrupt: ErrCorrupt}
+}
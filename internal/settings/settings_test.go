@@ -0,0 +1,113 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jdefrancesco/squares/internal/input"
+)
+
+func TestMissingFileIsDefault(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !s.Equal(Default()) {
+		t.Fatalf("expected defaults, got %+v", s)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "settings.json")
+	s := Default()
+	s.Fullscreen = true
+	s.FPSCap = 144
+	s.Controls = SchemeKeys
+	s.StickSensitivity = 1.5
+	s.Theme = ThemeDark
+	s.Colorblind = true
+	s.ShowMinimap = false
+	s.Language = "fr"
+	s.Bindings = s.Bindings.Without(input.Dash, input.MouseButton("Left")).With(input.Dash, input.MouseButton("Right"))
+	if err := Save(path, s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !got.Equal(s) {
+		t.Fatalf("loaded %+v, want %+v", got, s)
+	}
+}
+
+func TestLoadFillsGapsAndFixesRanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	data := `{"version": 1, "vsync": false, "mouse_sensitivity": 40, "theme": "neon", "music_volume": 0.2,
+		"bindings": {"quit": ["key:F10"]}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := Default()
+	want.VSync = false
+	want.MusicVolume = 0.2
+	want.Bindings = want.Bindings.Without(input.Quit, input.Key("Q")).With(input.Quit, input.Key("F10"))
+	if !s.Equal(want) {
+		t.Fatalf("loaded %+v, want %+v", s, want)
+	}
+}
+
+func TestLoadResetsClashingBindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	// Escape already pauses, so quitting on it would quit on every pause.
+	data := `{"version": 1, "bindings": {"quit": ["key:Escape"], "dash": ["key:Z"]}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := Default().Bindings
+	want[input.Dash] = []input.Control{input.Key("Z")}
+	if !s.Bindings.Equal(want) {
+		t.Fatalf("loaded bindings %v, want %v", s.Bindings, want)
+	}
+}
+
+func TestCorruptFileMovedAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	if !s.Equal(Default()) {
+		t.Fatalf("expected defaults after a corrupt file, got %+v", s)
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Fatalf("expected the bad file kept aside: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version": 99}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected a newer file to be refused, got %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected a newer file left in place: %v", err)
+	}
+}
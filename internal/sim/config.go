@@ -0,0 +1,55 @@
+package sim
+
+// RulesVersion identifies the game rules. Bump it with any change that
+// makes a seed and input stream play out differently, so old replays are
+// rejected instead of silently diverging.
+const RulesVersion = 5
+
+const (
+	// The simulation advances in fixed ticks, so identical inputs always
+	// produce identical runs.
+	TickRate = 60
+	TickDT   = 1.0 / TickRate
+
+	// Balance knobs live in Tuning; these are fixed by design.
+	playerRotationRate = 6.0
+	playerStartSize    = 22
+
+	// The forgiving hitbox is this fraction of the drawn player square.
+	forgivingHitboxScale = 0.90
+
+	// How much of the previous tick's smoothed movement carries into the
+	// next, and how much of it (px per tick) gives a dash a direction.
+	motionDecay   = 0.7
+	minDashMotion = 0.25
+
+	// After a dash in ModeClassic the player keeps its distance from the
+	// target, and each tick it keeps this fraction of what is left, so it
+	// drifts back under the cursor over a couple of seconds.
+	dashOffsetDecay = 0.985
+
+	// Entities this far outside the camera's view are dropped.
+	cullMargin = 160
+
+	// Each tick the camera closes this fraction of its distance to the
+	// player.
+	cameraFollow = 0.12
+
+	// Once the player is bigger than this fraction of the view's shorter
+	// side, the camera zooms out to keep it that size on screen. Each tick
+	// the zoom closes zoomFollow of the gap.
+	zoomFraction = 0.1
+	zoomFollow   = 0.05
+
+	// Spawn intervals are tuned for a view of this many square pixels.
+	// Bigger views spawn faster, in proportion to their edge, so they stay
+	// about as crowded; smaller ones slower.
+	referenceArea = DefaultWidth * DefaultHeight
+)
+
+// DefaultWidth and DefaultHeight are the reference arena and view size:
+// the game's starting window, and the size headless runs use.
+const (
+	DefaultWidth  = 640
+	DefaultHeight = 480
+)
@@ -0,0 +1,197 @@
+package sim
+
+import "math"
+
+// Number is a constraint that permits any numeric type
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Clamp returns value clamped to the range [low, high]
+func clamp[T Number](value, low, high T) T {
+	return max(low, min(value, high))
+}
+
+// box is a square hitbox centered on x, y and rotated by angle radians.
+type box struct {
+	x, y  float64
+	half  float64
+	angle float64
+}
+
+func entityBox(e Entity) box {
+	return box{x: e.X, y: e.Y, half: e.Size / 2}
+}
+
+// boxesIntersect is a separating axis test over the edge normals of both
+// boxes. Touching counts as intersecting.
+func boxesIntersect(a, b box) bool {
+	return boxGap(a, b) <= 0
+}
+
+// boxGap is how far apart a and b are along their most separating axis.
+// It is positive exactly when they are disjoint.
+func boxGap(a, b box) float64 {
+	dx := b.x - a.x
+	dy := b.y - a.y
+
+	aSin, aCos := math.Sincos(a.angle)
+	bSin, bCos := math.Sincos(b.angle)
+	axes := [4][2]float64{
+		{aCos, aSin}, {-aSin, aCos},
+		{bCos, bSin}, {-bSin, bCos},
+	}
+	gap := math.Inf(-1)
+	for _, n := range axes {
+		ra := a.half * (math.Abs(aCos*n[0]+aSin*n[1]) + math.Abs(-aSin*n[0]+aCos*n[1]))
+		rb := b.half * (math.Abs(bCos*n[0]+bSin*n[1]) + math.Abs(-bSin*n[0]+bCos*n[1]))
+		gap = math.Max(gap, math.Abs(dx*n[0]+dy*n[1])-(ra+rb))
+	}
+	return gap
+}
+
+// circleIntersectsBox finds the point of b closest to the circle by moving
+// the circle into b's rotated frame, where b is axis aligned.
+func circleIntersectsBox(circle Entity, b box) bool {
+	if b.angle == 0 {
+		return circleIntersectsSquare(circle, Entity{X: b.x, Y: b.y, Size: b.half * 2})
+	}
+
+	sin, cos := math.Sincos(b.angle)
+	dx := circle.X - b.x
+	dy := circle.Y - b.y
+	lx := dx*cos + dy*sin
+	ly := -dx*sin + dy*cos
+
+	px := lx - clamp(lx, -b.half, b.half)
+	py := ly - clamp(ly, -b.half, b.half)
+	r := circle.Size / 2
+	return px*px+py*py <= r*r
+}
+
+// circleBoxGap is the distance from the circle's edge to b, or minus its
+// radius once the center is inside b.
+func circleBoxGap(circle Entity, b box) float64 {
+	sin, cos := math.Sincos(b.angle)
+	dx := circle.X - b.x
+	dy := circle.Y - b.y
+	lx := dx*cos + dy*sin
+	ly := -dx*sin + dy*cos
+
+	px := lx - clamp(lx, -b.half, b.half)
+	py := ly - clamp(ly, -b.half, b.half)
+	return math.Hypot(px, py) - circle.Size/2
+}
+
+// Iterations for each search in sweep; each narrows the contact time by
+// at least a third.
+const sweepIters = 48
+
+// sweep finds the earliest t in [0, 1] with gap(t) <= 0. The gap between
+// two convex shapes moving in straight lines is convex in t, so a ternary
+// search finds the closest approach and the first contact lies before it,
+// where gap only falls.
+func sweep(gap func(t float64) float64) (float64, bool) {
+	if gap(0) <= 0 {
+		return 0, true
+	}
+
+	lo, hi := 0.0, 1.0
+	closest := -1.0
+	for i := 0; i < sweepIters && closest < 0; i++ {
+		m1 := lo + (hi-lo)/3
+		m2 := hi - (hi-lo)/3
+		g1, g2 := gap(m1), gap(m2)
+		switch {
+		case g1 <= 0:
+			closest = m1
+		case g2 <= 0:
+			closest = m2
+		case g1 <= g2:
+			hi = m2
+		default:
+			lo = m1
+		}
+	}
+	if closest < 0 {
+		if gap(1) > 0 {
+			return 0, false
+		}
+		closest = 1
+	}
+
+	lo, hi = 0, closest
+	for i := 0; i < sweepIters; i++ {
+		mid := (lo + hi) / 2
+		if gap(mid) <= 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, true
+}
+
+// sweptContact tests the player box moving from `from` to `to` against an
+// entity moving from e0 to e1 over the same tick, returning the fraction
+// of the tick at which they first touch.
+func sweptContact(from, to box, e0, e1 Entity) (float64, bool) {
+	// Skip the search when even bounding circles never get close enough.
+	reach := to.half*math.Sqrt2 + e1.Size/2
+	if e1.Kind == KindSquare {
+		reach = to.half*math.Sqrt2 + e1.Size/2*math.Sqrt2
+	}
+	if closestApproach(e0.X-from.x, e0.Y-from.y, e1.X-to.x, e1.Y-to.y) > reach {
+		return 0, false
+	}
+
+	lerp := func(a, b, t float64) float64 { return a*(1-t) + b*t }
+	return sweep(func(t float64) float64 {
+		p := to
+		p.x = lerp(from.x, to.x, t)
+		p.y = lerp(from.y, to.y, t)
+		e := e1
+		e.X = lerp(e0.X, e1.X, t)
+		e.Y = lerp(e0.Y, e1.Y, t)
+		if e.Kind == KindSquare {
+			return boxGap(p, entityBox(e))
+		}
+		return circleBoxGap(e, p)
+	})
+}
+
+// closestApproach is the distance from the origin to the segment from
+// (x0, y0) to (x1, y1).
+func closestApproach(x0, y0, x1, y1 float64) float64 {
+	dx, dy := x1-x0, y1-y0
+	t := 0.0
+	if l2 := dx*dx + dy*dy; l2 > 0 {
+		t = clamp(-(x0*dx+y0*dy)/l2, 0, 1)
+	}
+	return math.Hypot(x0+t*dx, y0+t*dy)
+}
+
+func squareIntersectsSquare(a, b Entity) bool {
+	ah := a.Size / 2
+	bh := b.Size / 2
+	return math.Abs(a.X-b.X) <= (ah+bh) && math.Abs(a.Y-b.Y) <= (ah+bh)
+}
+
+func circleIntersectsSquare(circle, square Entity) bool {
+	r := circle.Size / 2
+	half := square.Size / 2
+
+	minX := square.X - half
+	maxX := square.X + half
+	minY := square.Y - half
+	maxY := square.Y + half
+
+	cx := clamp(circle.X, minX, maxX)
+	cy := clamp(circle.Y, minY, maxY)
+
+	dx := circle.X - cx
+	dy := circle.Y - cy
+	return (dx*dx + dy*dy) <= r*r
+}
@@ -0,0 +1,212 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// TestClamp tests Clamp generic method.
+func TestClamp(t *testing.T) {
+	cases := []struct {
+		name      string
+		v, lo, hi int
+		want      int
+	}{
+		{"below", -1, 0, 10, 0},
+		{"inside", 5, 0, 10, 5},
+		{"above", 11, 0, 10, 10},
+	}
+
+	for _, tc := range cases {
+		if got := clamp(tc.v, tc.lo, tc.hi); got != tc.want {
+			t.Fatalf("%s: clamp(%v,%v,%v)=%v want %v", tc.name, tc.v, tc.lo, tc.hi, got, tc.want)
+		}
+	}
+
+	casesFloats := []struct {
+		name      string
+		v, lo, hi float64
+		want      float64
+	}{
+		{"below", -1, 0, 10, 0},
+		{"inside", 5, 0, 10, 5},
+		{"above", 11, 0, 10, 10},
+	}
+
+	fmt.Println("Now running float clamp test")
+	for _, tc := range casesFloats {
+		if got := clamp(tc.v, tc.lo, tc.hi); got != tc.want {
+			t.Fatalf("%s: clamp(%v,%v,%v)=%v want %v", tc.name, tc.v, tc.lo, tc.hi, got, tc.want)
+		}
+	}
+}
+
+func TestSquareIntersectsSquare(t *testing.T) {
+	a := Entity{X: 0, Y: 0, Size: 10}
+
+	b := Entity{X: 4, Y: 0, Size: 10}
+	if !squareIntersectsSquare(a, b) {
+		t.Fatalf("expected squares to intersect")
+	}
+
+	c := Entity{X: 10, Y: 0, Size: 10} // touching
+	if !squareIntersectsSquare(a, c) {
+		t.Fatalf("expected squares touching edges to intersect")
+	}
+
+	d := Entity{X: 10.001, Y: 0, Size: 10}
+	if squareIntersectsSquare(a, d) {
+		t.Fatalf("expected squares not to intersect")
+	}
+}
+
+func TestCircleIntersectsSquare(t *testing.T) {
+	sq := Entity{X: 0, Y: 0, Size: 2} // bounds [-1,1]
+
+	c1 := Entity{X: 0, Y: 0, Size: 2} // r=1
+	if !circleIntersectsSquare(c1, sq) {
+		t.Fatalf("expected circle center inside square to intersect")
+	}
+
+	c2 := Entity{X: 10, Y: 10, Size: 2}
+	if circleIntersectsSquare(c2, sq) {
+		t.Fatalf("expected far circle not to intersect")
+	}
+
+	// Tangent to right edge at (1,0): center at (2,0), r=1.
+	c3 := Entity{X: 2, Y: 0, Size: 2}
+	if !circleIntersectsSquare(c3, sq) {
+		t.Fatalf("expected tangent circle to count as intersecting")
+	}
+}
+
+func TestBoxesIntersectRotated(t *testing.T) {
+	player := box{half: 5}
+	rotated := box{half: 5, angle: math.Pi / 4}
+
+	// A small square just past the unrotated edge is reached only by the
+	// corner of the rotated square, which sticks out to 5*sqrt(2).
+	nearEdge := box{x: 6.5, half: 1}
+	if boxesIntersect(player, nearEdge) {
+		t.Fatalf("expected axis-aligned square to miss")
+	}
+	if !boxesIntersect(rotated, nearEdge) {
+		t.Fatalf("expected rotated corner to hit")
+	}
+
+	// Off the diagonal, the unrotated corner touches but the rotated
+	// square's edge is far away.
+	nearCorner := box{x: 5.5, y: 5.5, half: 0.5}
+	if !boxesIntersect(player, nearCorner) {
+		t.Fatalf("expected axis-aligned corners to touch")
+	}
+	if boxesIntersect(rotated, nearCorner) {
+		t.Fatalf("expected rotated square to miss the diagonal")
+	}
+
+	// Two rotated boxes: order must not matter.
+	other := box{x: 9, y: 1, half: 3, angle: 0.3}
+	if boxesIntersect(rotated, other) != boxesIntersect(other, rotated) {
+		t.Fatalf("expected SAT to be symmetric")
+	}
+}
+
+func TestBoxesIntersectMatchesAxisAligned(t *testing.T) {
+	a := Entity{X: 0, Y: 0, Size: 10}
+	for _, x := range []float64{4, 10, 10.001} {
+		b := Entity{X: x, Y: 0, Size: 10}
+		if got, want := boxesIntersect(entityBox(a), entityBox(b)), squareIntersectsSquare(a, b); got != want {
+			t.Fatalf("x=%v: SAT=%v, axis-aligned=%v", x, got, want)
+		}
+	}
+}
+
+func TestCircleIntersectsRotatedBox(t *testing.T) {
+	sq := box{half: 5}
+	rotated := box{half: 5, angle: math.Pi / 4}
+
+	// Just outside the unrotated right edge, inside the rotated corner.
+	c := Entity{X: 6.8, Y: 0, Size: 1}
+	if circleIntersectsBox(c, sq) {
+		t.Fatalf("expected circle to miss the axis-aligned square")
+	}
+	if !circleIntersectsBox(c, rotated) {
+		t.Fatalf("expected circle to hit the rotated corner")
+	}
+
+	// Near the unrotated corner, clear of the rotated edge.
+	d := Entity{X: 5.3, Y: 5.3, Size: 1}
+	if !circleIntersectsBox(d, sq) {
+		t.Fatalf("expected circle to touch the axis-aligned corner")
+	}
+	if circleIntersectsBox(d, rotated) {
+		t.Fatalf("expected circle to miss the rotated edge")
+	}
+}
+
+func TestSweptContactTunneling(t *testing.T) {
+	// The player jumps 200px right in one tick, straight over a hazard
+	// that neither end position touches.
+	from := box{x: 0, y: 100, half: 10}
+	to := box{x: 200, y: 100, half: 10}
+	hazard := Entity{Kind: KindCircleHazard, X: 100, Y: 100, Size: 20}
+
+	if circleIntersectsBox(hazard, from) || circleIntersectsBox(hazard, to) {
+		t.Fatalf("test setup: hazard should miss both end positions")
+	}
+	got, ok := sweptContact(from, to, hazard, hazard)
+	if !ok {
+		t.Fatalf("expected the swept path to hit the hazard")
+	}
+	// First contact when the box's right edge reaches the circle's left.
+	if want := (100 - 10 - 10) / 200.0; math.Abs(got-want) > 1e-6 {
+		t.Fatalf("contact at t=%v, want %v", got, want)
+	}
+
+	// The same flick 30px lower clears it.
+	from.y, to.y = 130, 130
+	if _, ok := sweptContact(from, to, hazard, hazard); ok {
+		t.Fatalf("expected a path 30px below to miss")
+	}
+}
+
+func TestSweptContactMovingEntity(t *testing.T) {
+	// A still player and a square crossing it within one tick.
+	p := box{x: 100, y: 100, half: 10}
+	e0 := Entity{Kind: KindSquare, X: 100, Y: 0, Size: 10}
+	e1 := Entity{Kind: KindSquare, X: 100, Y: 200, Size: 10}
+
+	got, ok := sweptContact(p, p, e0, e1)
+	if !ok {
+		t.Fatalf("expected the crossing square to hit")
+	}
+	if want := (100 - 10 - 5) / 200.0; math.Abs(got-want) > 1e-6 {
+		t.Fatalf("contact at t=%v, want %v", got, want)
+	}
+
+	// Moving alongside each other, they never meet.
+	from, to := box{x: 0, y: 100, half: 10}, box{x: 200, y: 100, half: 10}
+	e0 = Entity{Kind: KindSquare, X: 0, Y: 130, Size: 10}
+	e1 = Entity{Kind: KindSquare, X: 200, Y: 130, Size: 10}
+	if _, ok := sweptContact(from, to, e0, e1); ok {
+		t.Fatalf("expected parallel paths not to touch")
+	}
+}
+
+func TestSweptContactAgreesAtRest(t *testing.T) {
+	// Without motion the sweep is just the overlap test.
+	p := box{x: 50, y: 50, half: 10, angle: math.Pi / 4}
+	for x := 20.0; x <= 80; x += 5 {
+		sq := Entity{Kind: KindSquare, X: x, Y: 50, Size: 8}
+		_, ok := sweptContact(p, p, sq, sq)
+		if want := boxesIntersect(p, entityBox(sq)); ok != want {
+			t.Fatalf("square at x=%v: swept %v, overlap %v", x, ok, want)
+		}
+		c := Entity{Kind: KindCircleHazard, X: x, Y: 50, Size: 8}
+		_, ok = sweptContact(p, p, c, c)
+		if want := circleIntersectsBox(c, p); ok != want {
+			t.Fatalf("circle at x=%v: swept %v, overlap %v", x, ok, want)
+		}
+	}
+}
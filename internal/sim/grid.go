@@ -0,0 +1,134 @@
+package sim
+
+import (
+	"math"
+	"slices"
+)
+
+// gridCell is the side of a broadphase cell in pixels: a bit bigger than
+// most entities, so each one lands in one to four cells.
+const gridCell = 64
+
+// aabb is an axis-aligned bounding box.
+type aabb struct {
+	minX, minY, maxX, maxY float64
+}
+
+func (a aabb) overlaps(b aabb) bool {
+	return a.minX <= b.maxX && b.minX <= a.maxX && a.minY <= b.maxY && b.minY <= a.maxY
+}
+
+func (a aabb) contains(x, y float64) bool {
+	return a.minX <= x && x <= a.maxX && a.minY <= y && y <= a.maxY
+}
+
+// grow returns a expanded by d on every side.
+func (a aabb) grow(d float64) aabb {
+	return aabb{a.minX - d, a.minY - d, a.maxX + d, a.maxY + d}
+}
+
+// boundsOf is the bounding box of an entity as drawn: exact for squares,
+// circumscribing for circles.
+func boundsOf(e Entity) aabb {
+	h := e.Size / 2
+	return aabb{e.X - h, e.Y - h, e.X + h, e.Y + h}
+}
+
+// grid is a uniform-grid broadphase. Items are indices into a slice the
+// caller owns; the grid keeps their bounds and which cells those cover.
+// It is rebuilt every tick, reusing its allocations.
+type grid struct {
+	x0, y0     float64
+	cols, rows int
+	cells      [][]int32
+	bounds     []aabb
+
+	// seen[i] == stamp marks item i as already reported by this query.
+	seen  []uint32
+	stamp uint32
+}
+
+// reset empties g and sizes it to cover area. Bounds outside area are
+// clamped into its edge cells, so nothing is ever lost, just found slower.
+func (g *grid) reset(area aabb) {
+	g.x0, g.y0 = area.minX, area.minY
+	cols := max(1, int(math.Ceil((area.maxX-area.minX)/gridCell)))
+	rows := max(1, int(math.Ceil((area.maxY-area.minY)/gridCell)))
+	if cols*rows != len(g.cells) {
+		g.cells = make([][]int32, cols*rows)
+	}
+	for i := range g.cells {
+		g.cells[i] = g.cells[i][:0]
+	}
+	g.cols, g.rows = cols, rows
+	g.bounds = g.bounds[:0]
+}
+
+func (g *grid) cellRange(b aabb) (c0, r0, c1, r1 int) {
+	cell := func(v, origin float64, n int) int {
+		return clamp(int(math.Floor((v-origin)/gridCell)), 0, n-1)
+	}
+	return cell(b.minX, g.x0, g.cols), cell(b.minY, g.y0, g.rows),
+		cell(b.maxX, g.x0, g.cols), cell(b.maxY, g.y0, g.rows)
+}
+
+// insert adds the next item, numbered from 0 in insertion order.
+func (g *grid) insert(b aabb) {
+	i := int32(len(g.bounds))
+	g.bounds = append(g.bounds, b)
+	c0, r0, c1, r1 := g.cellRange(b)
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			g.cells[r*g.cols+c] = append(g.cells[r*g.cols+c], i)
+		}
+	}
+}
+
+// query appends to dst every item whose bounds overlap b, each once and in
+// ascending order, so callers stay deterministic.
+func (g *grid) query(dst []int, b aabb) []int {
+	return g.collect(dst, b, func(o aabb) bool { return o.overlaps(b) })
+}
+
+// queryRadius appends to dst every item whose bounds come within r of
+// (x, y), each once and in ascending order.
+func (g *grid) queryRadius(dst []int, x, y, r float64) []int {
+	return g.collect(dst, aabb{x, y, x, y}.grow(r), func(o aabb) bool {
+		dx := x - clamp(x, o.minX, o.maxX)
+		dy := y - clamp(y, o.minY, o.maxY)
+		return dx*dx+dy*dy <= r*r
+	})
+}
+
+func (g *grid) collect(dst []int, b aabb, keep func(aabb) bool) []int {
+	if len(g.cells) == 0 {
+		return dst
+	}
+	if len(g.seen) < len(g.bounds) {
+		g.seen = make([]uint32, len(g.bounds)*2)
+		g.stamp = 0
+	}
+	g.stamp++
+	if g.stamp == 0 {
+		clear(g.seen)
+		g.stamp = 1
+	}
+
+	start := len(dst)
+	c0, r0, c1, r1 := g.cellRange(b)
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			for _, i := range g.cells[r*g.cols+c] {
+				if g.seen[i] == g.stamp {
+					continue
+				}
+				g.seen[i] = g.stamp
+				if keep(g.bounds[i]) {
+					dst = append(dst, int(i))
+				}
+			}
+		}
+	}
+	slices.Sort(dst[start:])
+	return dst
+}
@@ -0,0 +1,76 @@
+package sim
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestGridMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	area := aabb{-160, -160, 800, 640}
+
+	var g grid
+	for round := 0; round < 3; round++ {
+		g.reset(area)
+		var all []aabb
+		// Some bounds stray outside the area, which the grid must still find.
+		for i := 0; i < 500; i++ {
+			x := rng.Float64()*1200 - 300
+			y := rng.Float64()*1000 - 250
+			h := 2 + rng.Float64()*60
+			b := aabb{x - h, y - h, x + h, y + h}
+			all = append(all, b)
+			g.insert(b)
+		}
+
+		for q := 0; q < 200; q++ {
+			x := rng.Float64()*1200 - 300
+			y := rng.Float64()*1000 - 250
+			r := rng.Float64() * 120
+
+			box := aabb{x, y, x, y}.grow(r)
+			var want []int
+			for i, b := range all {
+				if b.overlaps(box) {
+					want = append(want, i)
+				}
+			}
+			if got := g.query(nil, box); !slices.Equal(got, want) {
+				t.Fatalf("round %d: query %+v = %v, want %v", round, box, got, want)
+			}
+
+			want = want[:0]
+			for i, b := range all {
+				dx := x - clamp(x, b.minX, b.maxX)
+				dy := y - clamp(y, b.minY, b.maxY)
+				if dx*dx+dy*dy <= r*r {
+					want = append(want, i)
+				}
+			}
+			if got := g.queryRadius(nil, x, y, r); !slices.Equal(got, want) {
+				t.Fatalf("round %d: queryRadius(%v, %v, %v) = %v, want %v", round, x, y, r, got, want)
+			}
+		}
+	}
+}
+
+func TestEntitiesNear(t *testing.T) {
+	w := newTestWorld(1)
+	if got := w.EntitiesNear(nil, 320, 240, 50); len(got) != 0 {
+		t.Fatalf("expected nothing near before the first step, got %v", got)
+	}
+
+	w.ents = append(w.ents,
+		Entity{Kind: KindCircleHazard, X: 100, Y: 100, Size: 20},
+		Entity{Kind: KindSquare, X: 500, Y: 400, Size: 10},
+	)
+	w.Step(Input{Target: true, X: 320, Y: 240})
+
+	if got := w.EntitiesNear(nil, 120, 100, 15); !slices.Equal(got, []int{0}) {
+		t.Fatalf("expected the hazard near (120, 100), got %v", got)
+	}
+	if got := w.EntitiesNear(nil, 320, 240, 100); len(got) != 0 {
+		t.Fatalf("expected nothing near the center, got %v", got)
+	}
+}
@@ -0,0 +1,42 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode selects how the player moves. Everything else about a run is set by
+// the tuning.
+type Mode string
+
+const (
+	// ModeClassic snaps the player to the cursor and steers it at a fixed
+	// speed.
+	ModeClassic Mode = "classic"
+	// ModeInertia accelerates the player toward the cursor or along the
+	// steering direction, lets it coast to a stop, and caps its speed lower
+	// the bigger it gets.
+	ModeInertia Mode = "inertia"
+)
+
+// Modes lists every mode, default first.
+func Modes() []Mode {
+	return []Mode{ModeClassic, ModeInertia}
+}
+
+// ParseMode looks up a mode by name. The empty string is ModeClassic.
+func ParseMode(name string) (Mode, error) {
+	if name == "" {
+		return ModeClassic, nil
+	}
+	for _, m := range Modes() {
+		if string(m) == name {
+			return m, nil
+		}
+	}
+	names := make([]string, len(Modes()))
+	for i, m := range Modes() {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("unknown mode %q (have %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,125 @@
+package sim
+
+import (
+	"image/color"
+	"math"
+)
+
+// spawnEntityWithDifficulty spawns an entity just off one edge of the
+// camera's view, heading roughly for the player. Distances and speeds grow
+// with the zoom, so the view looks the same however far out it is.
+func (w *World) spawnEntityWithDifficulty(d float64) {
+	edge := w.rng.Intn(4)
+	margin := 50.0 * w.zoom
+	v := w.view()
+	vw, vh := w.ViewSize()
+
+	var x, y float64
+	switch edge {
+	case 0:
+		x = v.minX + w.rng.Float64()*vw
+		y = v.minY - margin
+	case 1:
+		x = v.minX + w.rng.Float64()*vw
+		y = v.maxY + margin
+	case 2:
+		x = v.minX - margin
+		y = v.minY + w.rng.Float64()*vh
+	case 3:
+		x = v.maxX + margin
+		y = v.minY + w.rng.Float64()*vh
+	}
+
+	t := &w.t
+	hazardP := t.HazardP.At(d)
+	boostP := t.BoostP.At(d)
+
+	forceEdible := w.spawnsSinceEdible >= t.MaxSpawnsWithoutEdible
+
+	kind := KindSquare
+	if !forceEdible {
+		r := w.rng.Float64()
+		switch {
+		case r < hazardP:
+			kind = KindCircleHazard
+		case r < hazardP+boostP:
+			kind = KindCircleBoost
+		default:
+			kind = KindSquare
+		}
+	}
+
+	p := w.player.Size
+	var size float64
+
+	if kind == KindSquare {
+		edibleBias := t.EdibleBias.At(d)
+		isEdible := forceEdible || (w.rng.Float64() < edibleBias)
+
+		if isEdible {
+			size = p * t.EdibleSize.lerp(w.rng.Float64())
+			size = math.Max(t.EdibleFloor, size)
+			w.spawnsSinceEdible = 0
+		} else {
+			threatMin := t.ThreatMin
+			threatMax := t.ThreatMax.At(d)
+			size = p*(threatMin+w.rng.Float64()*(threatMax-threatMin)) + t.ThreatMargin
+			w.spawnsSinceEdible++
+		}
+	} else {
+		minS := math.Max(t.CircleFloor, p*t.CircleMinScale)
+		maxS := p*t.CircleMaxScale + t.CircleMaxPad
+		size = minS + w.rng.Float64()*(maxS-minS)
+		w.spawnsSinceEdible++
+	}
+
+	dx := w.player.X - x
+	dy := w.player.Y - y
+	dist := math.Hypot(dx, dy)
+	if dist < 1 {
+		dist = 1
+	}
+	dx /= dist
+	dy /= dist
+
+	baseK := t.BaseK.At(d)
+	speed := baseK/math.Sqrt(size) + (w.rng.Float64()*2*t.SpeedNoise - t.SpeedNoise)
+	speed = clamp(speed, t.Speed.Min, t.Speed.Max) * w.zoom
+
+	switch kind {
+	case KindCircleHazard:
+		speed *= t.HazardMul
+	case KindCircleBoost:
+		speed *= t.BoostMul
+	}
+
+	jitter := t.Jitter.At(d)
+	dx += (w.rng.Float64()*2 - 1) * jitter
+	dy += (w.rng.Float64()*2 - 1) * jitter
+	nd := math.Hypot(dx, dy)
+	if nd < 1 {
+		nd = 1
+	}
+	dx /= nd
+	dy /= nd
+
+	var col color.RGBA
+	switch kind {
+	case KindSquare:
+		col = color.RGBA{0, 0, 0, 255}
+	case KindCircleHazard:
+		col = color.RGBA{15, 15, 15, 255}
+	case KindCircleBoost:
+		col = color.RGBA{40, 150, 165, 255}
+	}
+
+	w.ents = append(w.ents, Entity{
+		Kind: kind,
+		X:    x,
+		Y:    y,
+		Size: size,
+		VX:   dx * speed,
+		VY:   dy * speed,
+		Col:  col,
+	})
+}
@@ -0,0 +1,113 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpawnForceEdibleAlwaysSquare(t *testing.T) {
+	w := New(Options{Seed: 1, Width: 640, Height: 480})
+	w.player.Size = 30
+	w.spawnsSinceEdible = w.t.MaxSpawnsWithoutEdible
+
+	before := len(w.ents)
+	w.spawnEntityWithDifficulty(0)
+	if len(w.ents) != before+1 {
+		t.Fatalf("expected 1 entity appended, got %d", len(w.ents)-before)
+	}
+
+	e := w.ents[len(w.ents)-1]
+	if e.Kind != KindSquare {
+		t.Fatalf("expected forced-edible spawn to be KindSquare, got %v", e.Kind)
+	}
+	if w.spawnsSinceEdible != 0 {
+		t.Fatalf("expected spawnsSinceEdible reset to 0, got %d", w.spawnsSinceEdible)
+	}
+	if e.Col.A != 255 {
+		t.Fatalf("expected alpha=255, got %d", e.Col.A)
+	}
+	if e.Col.R != 0 || e.Col.G != 0 || e.Col.B != 0 {
+		t.Fatalf("expected square color to be black, got %+v", e.Col)
+	}
+
+	// Forced-edible squares should be smaller than the player.
+	if e.Size < 10 {
+		t.Fatalf("expected edible square size >= 10, got %v", e.Size)
+	}
+	if e.Size >= w.player.Size {
+		t.Fatalf("expected edible square smaller than player (player=%v, square=%v)", w.player.Size, e.Size)
+	}
+}
+
+func TestSpawnCircleKindsAndColorsAppear(t *testing.T) {
+	w := New(Options{Seed: 2, Width: 640, Height: 480})
+	w.player.Size = 30
+
+	seenBoost := false
+	seenHazard := false
+
+	for i := 0; i < 800; i++ {
+		// Avoid being forced into edible-only squares.
+		w.spawnsSinceEdible = 0
+		w.spawnEntityWithDifficulty(0)
+		e := w.ents[len(w.ents)-1]
+
+		switch e.Kind {
+		case KindCircleBoost:
+			seenBoost = true
+			if e.Col.R != 40 || e.Col.G != 150 || e.Col.B != 165 || e.Col.A != 255 {
+				t.Fatalf("unexpected boost color: %+v", e.Col)
+			}
+		case KindCircleHazard:
+			seenHazard = true
+			if e.Col.R != 15 || e.Col.G != 15 || e.Col.B != 15 || e.Col.A != 255 {
+				t.Fatalf("unexpected hazard color: %+v", e.Col)
+			}
+		}
+
+		if seenBoost && seenHazard {
+			break
+		}
+	}
+
+	if !seenBoost {
+		t.Fatalf("expected to see at least one boost circle spawn")
+	}
+	if !seenHazard {
+		t.Fatalf("expected to see at least one hazard circle spawn")
+	}
+}
+
+func TestSpawnVelocityHasReasonableMagnitude(t *testing.T) {
+	w := New(Options{Seed: 3, Width: 640, Height: 480})
+	w.player.Size = 40
+	w.spawnsSinceEdible = 0
+
+	w.spawnEntityWithDifficulty(200)
+	e := w.ents[len(w.ents)-1]
+
+	speed := math.Hypot(e.VX, e.VY)
+	if speed <= 0 {
+		t.Fatalf("expected speed > 0")
+	}
+	// Internal clamp is [75,340] with small kind multipliers; allow a little headroom.
+	if speed > 450 {
+		t.Fatalf("expected speed to be bounded, got %v", speed)
+	}
+}
+
+func TestSpawnSameSeedSameSequence(t *testing.T) {
+	a := New(Options{Seed: 42, Width: 640, Height: 480})
+	b := New(Options{Seed: 42, Width: 640, Height: 480})
+
+	for i := 0; i < 50; i++ {
+		a.spawnEntityWithDifficulty(float64(i))
+		b.spawnEntityWithDifficulty(float64(i))
+	}
+
+	for i := range a.ents {
+		if a.ents[i] != b.ents[i] {
+			t.Fatalf("spawn %d differs for the same seed: %+v vs %+v", i, a.ents[i], b.ents[i])
+		}
+	}
+}
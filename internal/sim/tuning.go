@@ -0,0 +1,281 @@
+package sim
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+//go:embed tuning.json
+var defaultTuningJSON []byte
+
+// Curve is a knob that ramps with difficulty:
+// clamp(Base + PerDifficulty*d, Min, Max).
+type Curve struct {
+	Base          float64 `json:"base"`
+	PerDifficulty float64 `json:"per_difficulty"`
+	Min           float64 `json:"min"`
+	Max           float64 `json:"max"`
+}
+
+func (c Curve) At(d float64) float64 {
+	return clamp(c.Base+c.PerDifficulty*d, c.Min, c.Max)
+}
+
+// Range is an interval values are drawn uniformly from.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+func (r Range) lerp(t float64) float64 {
+	return r.Min + t*(r.Max-r.Min)
+}
+
+// Easing shapes how a dash covers its distance over its duration.
+type Easing string
+
+const (
+	EaseLinear Easing = "linear"
+	// EaseOut starts fast and settles gently.
+	EaseOut Easing = "out"
+	// EaseInOut starts and ends gently.
+	EaseInOut Easing = "in_out"
+)
+
+// At maps progress p in [0, 1] to the fraction of the distance covered.
+func (e Easing) At(p float64) float64 {
+	switch e {
+	case EaseOut:
+		q := 1 - p
+		return 1 - q*q*q
+	case EaseInOut:
+		return p * p * (3 - 2*p)
+	}
+	return p
+}
+
+// Dash is a short burst of movement that briefly protects the player.
+type Dash struct {
+	// Distance in px, covered over Duration seconds.
+	Distance float64 `json:"distance"`
+	Duration float64 `json:"duration"`
+	Easing   Easing  `json:"easing"`
+	// IFrames is how long the player is invulnerable from the start of a dash.
+	IFrames  float64 `json:"iframes"`
+	Cooldown float64 `json:"cooldown"`
+}
+
+// Inertia is how the player handles in ModeInertia. Speeds are in px/s,
+// rates in px/s².
+type Inertia struct {
+	// Accel is how quickly the player changes velocity while steering, and
+	// Friction how quickly it stops when not.
+	Accel    float64 `json:"accel"`
+	Friction float64 `json:"friction"`
+	// The top speed is MaxSpeed at the starting size, scaled by
+	// (start size / size)^SizeFalloff as the player grows, but never below
+	// MinSpeed.
+	MaxSpeed    float64 `json:"max_speed"`
+	MinSpeed    float64 `json:"min_speed"`
+	SizeFalloff float64 `json:"size_falloff"`
+	// Chasing the cursor, the player aims for Arrive times its distance
+	// per second, so it slows down on approach instead of overshooting.
+	Arrive float64 `json:"arrive"`
+}
+
+// TopSpeed is the speed cap for a player of the given size.
+func (in Inertia) TopSpeed(size float64) float64 {
+	return math.Max(in.MinSpeed, in.MaxSpeed*math.Pow(playerStartSize/size, in.SizeFalloff))
+}
+
+// Hitbox selects how the player's collision shape relates to its sprite.
+type Hitbox string
+
+const (
+	// HitboxForgiving ignores rotation and shrinks the square a little,
+	// so grazing a threat is survivable.
+	HitboxForgiving Hitbox = "forgiving"
+	// HitboxVisual collides with exactly the rotated square that is drawn.
+	HitboxVisual Hitbox = "visual"
+)
+
+// Tuning holds every balance knob. The defaults are embedded from
+// tuning.json; a file passed to LoadTuning only needs the fields it
+// changes.
+type Tuning struct {
+	Dash               Dash    `json:"dash"`
+	InvincibleDuration float64 `json:"invincible_duration"`
+	SteerSpeed         float64 `json:"steer_speed"`
+	Inertia            Inertia `json:"inertia"`
+	Hitbox             Hitbox  `json:"hitbox"`
+
+	GrowthScale float64 `json:"growth_scale"`
+	GrowthFlat  float64 `json:"growth_flat"`
+
+	// Difficulty is TimeWeight*seconds + ScoreWeight*score; every Curve
+	// below is a function of it.
+	Difficulty struct {
+		TimeWeight  float64 `json:"time_weight"`
+		ScoreWeight float64 `json:"score_weight"`
+	} `json:"difficulty"`
+
+	SpawnInterval          Curve `json:"spawn_interval"`
+	MaxSpawnsWithoutEdible int   `json:"max_spawns_without_edible"`
+
+	// Chance a spawn is a hazard or boost circle; the rest are squares.
+	HazardP Curve `json:"hazard_p"`
+	BoostP  Curve `json:"boost_p"`
+	// Chance a spawned square is smaller than the player.
+	EdibleBias Curve `json:"edible_bias"`
+
+	// Square sizes, as multiples of the player size.
+	EdibleSize   Range   `json:"edible_size"`
+	EdibleFloor  float64 `json:"edible_floor"`
+	ThreatMin    float64 `json:"threat_min"`
+	ThreatMax    Curve   `json:"threat_max"`
+	ThreatMargin float64 `json:"threat_margin"`
+
+	// Circle sizes: from max(CircleFloor, player*CircleMinScale) to
+	// player*CircleMaxScale + CircleMaxPad.
+	CircleFloor    float64 `json:"circle_floor"`
+	CircleMinScale float64 `json:"circle_min_scale"`
+	CircleMaxScale float64 `json:"circle_max_scale"`
+	CircleMaxPad   float64 `json:"circle_max_pad"`
+
+	// Speed is BaseK/sqrt(size) plus up to ±SpeedNoise, clamped to Speed.
+	BaseK      Curve   `json:"base_k"`
+	SpeedNoise float64 `json:"speed_noise"`
+	Speed      Range   `json:"speed"`
+	HazardMul  float64 `json:"hazard_speed_mul"`
+	BoostMul   float64 `json:"boost_speed_mul"`
+	// How far spawns stray from heading straight at the player.
+	Jitter Curve `json:"jitter"`
+}
+
+// DefaultTuning returns the built-in balance.
+func DefaultTuning() Tuning {
+	t, err := ParseTuning(defaultTuningJSON, Tuning{})
+	if err != nil {
+		panic("sim: embedded tuning.json: " + err.Error())
+	}
+	return t
+}
+
+// LoadTuning reads a tuning file. Fields it leaves out keep their default.
+func LoadTuning(path string) (Tuning, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Tuning{}, err
+	}
+	t, err := ParseTuning(data, DefaultTuning())
+	if err != nil {
+		return Tuning{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// ParseTuning overlays the JSON in data onto base and validates the result.
+// Unknown fields are rejected so a typo can't silently do nothing.
+func ParseTuning(data []byte, base Tuning) (Tuning, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	t := base
+	if err := dec.Decode(&t); err != nil {
+		return Tuning{}, fmt.Errorf("parsing tuning: %w", err)
+	}
+	if err := dec.Decode(&json.RawMessage{}); err != io.EOF {
+		return Tuning{}, errors.New("parsing tuning: data after the object")
+	}
+	if err := t.Validate(); err != nil {
+		return Tuning{}, err
+	}
+	return t, nil
+}
+
+// Validate reports every value that would break the game, one per line.
+func (t Tuning) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	positive := func(name string, v float64) {
+		check(v > 0, "%s must be > 0, got %v", name, v)
+	}
+	nonNegative := func(name string, v float64) {
+		check(v >= 0, "%s must be >= 0, got %v", name, v)
+	}
+	curve := func(name string, c Curve) {
+		check(c.Min <= c.Max, "%s: min (%v) must not exceed max (%v)", name, c.Min, c.Max)
+	}
+	probability := func(name string, c Curve) {
+		curve(name, c)
+		check(c.Min >= 0 && c.Max <= 1, "%s: min and max must be within [0, 1], got [%v, %v]", name, c.Min, c.Max)
+	}
+
+	nonNegative("dash.distance", t.Dash.Distance)
+	positive("dash.duration", t.Dash.Duration)
+	check(t.Dash.Easing == EaseLinear || t.Dash.Easing == EaseOut || t.Dash.Easing == EaseInOut,
+		"dash.easing must be %q, %q or %q, got %q", EaseLinear, EaseOut, EaseInOut, t.Dash.Easing)
+	nonNegative("dash.iframes", t.Dash.IFrames)
+	nonNegative("dash.cooldown", t.Dash.Cooldown)
+	nonNegative("invincible_duration", t.InvincibleDuration)
+	positive("steer_speed", t.SteerSpeed)
+	positive("inertia.accel", t.Inertia.Accel)
+	positive("inertia.friction", t.Inertia.Friction)
+	positive("inertia.min_speed", t.Inertia.MinSpeed)
+	check(t.Inertia.MinSpeed <= t.Inertia.MaxSpeed, "inertia: min_speed (%v) must not exceed max_speed (%v)", t.Inertia.MinSpeed, t.Inertia.MaxSpeed)
+	nonNegative("inertia.size_falloff", t.Inertia.SizeFalloff)
+	positive("inertia.arrive", t.Inertia.Arrive)
+	check(t.Hitbox == HitboxForgiving || t.Hitbox == HitboxVisual,
+		"hitbox must be %q or %q, got %q", HitboxForgiving, HitboxVisual, t.Hitbox)
+	nonNegative("growth_scale", t.GrowthScale)
+	nonNegative("growth_flat", t.GrowthFlat)
+	nonNegative("difficulty.time_weight", t.Difficulty.TimeWeight)
+	nonNegative("difficulty.score_weight", t.Difficulty.ScoreWeight)
+
+	curve("spawn_interval", t.SpawnInterval)
+	positive("spawn_interval.min", t.SpawnInterval.Min)
+	check(t.MaxSpawnsWithoutEdible >= 0, "max_spawns_without_edible must be >= 0, got %d", t.MaxSpawnsWithoutEdible)
+
+	probability("hazard_p", t.HazardP)
+	probability("boost_p", t.BoostP)
+	check(t.HazardP.Max+t.BoostP.Max <= 1, "hazard_p.max + boost_p.max must not exceed 1, got %v", t.HazardP.Max+t.BoostP.Max)
+	probability("edible_bias", t.EdibleBias)
+
+	check(t.EdibleSize.Min <= t.EdibleSize.Max, "edible_size: min (%v) must not exceed max (%v)", t.EdibleSize.Min, t.EdibleSize.Max)
+	positive("edible_size.min", t.EdibleSize.Min)
+	check(t.EdibleSize.Max < 1, "edible_size.max must be < 1 so edible squares are smaller than the player, got %v", t.EdibleSize.Max)
+	positive("edible_floor", t.EdibleFloor)
+	curve("threat_max", t.ThreatMax)
+	check(t.ThreatMin >= 1, "threat_min must be >= 1 so threats are at least player-sized, got %v", t.ThreatMin)
+	check(t.ThreatMin <= t.ThreatMax.Min, "threat_min (%v) must not exceed threat_max.min (%v)", t.ThreatMin, t.ThreatMax.Min)
+	nonNegative("threat_margin", t.ThreatMargin)
+
+	positive("circle_floor", t.CircleFloor)
+	nonNegative("circle_min_scale", t.CircleMinScale)
+	positive("circle_max_scale", t.CircleMaxScale)
+	nonNegative("circle_max_pad", t.CircleMaxPad)
+
+	curve("base_k", t.BaseK)
+	positive("base_k.min", t.BaseK.Min)
+	nonNegative("speed_noise", t.SpeedNoise)
+	check(t.Speed.Min <= t.Speed.Max, "speed: min (%v) must not exceed max (%v)", t.Speed.Min, t.Speed.Max)
+	positive("speed.min", t.Speed.Min)
+	positive("hazard_speed_mul", t.HazardMul)
+	positive("boost_speed_mul", t.BoostMul)
+	curve("jitter", t.Jitter)
+	nonNegative("jitter.min", t.Jitter.Min)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid tuning:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package sim
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTuningIsValid(t *testing.T) {
+	if err := DefaultTuning().Validate(); err != nil {
+		t.Fatalf("embedded defaults do not validate: %v", err)
+	}
+}
+
+func TestCurveAt(t *testing.T) {
+	c := Curve{Base: 0.85, PerDifficulty: -0.0035, Min: 0.25, Max: 0.85}
+	cases := []struct{ d, want float64 }{
+		{0, 0.85},
+		{100, 0.5},
+		{1000, 0.25},
+		{-10, 0.85},
+	}
+	for _, tc := range cases {
+		if got := c.At(tc.d); math.Abs(got-tc.want) > 1e-12 {
+			t.Fatalf("At(%v)=%v want %v", tc.d, got, tc.want)
+		}
+	}
+}
+
+func TestLoadTuningOverlaysDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuning.json")
+	os.WriteFile(path, []byte(`{"growth_scale": 0.1, "hazard_p": {"max": 0.3}}`), 0o644)
+
+	got, err := LoadTuning(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	def := DefaultTuning()
+	if got.GrowthScale != 0.1 || got.HazardP.Max != 0.3 {
+		t.Fatalf("overrides not applied: %+v", got)
+	}
+	if got.HazardP.Base != def.HazardP.Base || got.Dash != def.Dash {
+		t.Fatalf("expected untouched fields to keep their defaults")
+	}
+}
+
+func TestEasing(t *testing.T) {
+	for _, e := range []Easing{EaseLinear, EaseOut, EaseInOut} {
+		if e.At(0) != 0 || e.At(1) != 1 {
+			t.Fatalf("%s: expected to run from 0 to 1, got %v to %v", e, e.At(0), e.At(1))
+		}
+		for p := 0.1; p < 1; p += 0.1 {
+			if e.At(p) <= e.At(p-0.1) {
+				t.Fatalf("%s: expected to keep moving forward at %v", e, p)
+			}
+		}
+	}
+}
+
+func TestParseTuningErrors(t *testing.T) {
+	cases := []struct {
+		name, json, want string
+	}{
+		{"unknown field", `{"growth_scael": 1}`, "growth_scael"},
+		{"bad type", `{"growth_scale": "big"}`, "growth_scale"},
+		{"inverted curve", `{"jitter": {"min": 0.5, "max": 0.1}}`, "jitter: min (0.5) must not exceed max (0.1)"},
+		{"probabilities", `{"hazard_p": {"max": 0.7}, "boost_p": {"max": 0.5}}`, "hazard_p.max + boost_p.max"},
+		{"zero interval", `{"spawn_interval": {"min": 0}}`, "spawn_interval.min must be > 0"},
+		{"bad easing", `{"dash": {"easing": "bouncy"}}`, "dash.easing"},
+		{"old dash field", `{"dash_cooldown": 2}`, "dash_cooldown"},
+		{"trailing data", `{"growth_scale": 0.1} {}`, "data after the object"},
+		{"trailing brace", `{"growth_scale": 0.1}}`, "data after the object"},
+	}
+	for _, tc := range cases {
+		_, err := ParseTuning([]byte(tc.json), DefaultTuning())
+		if err == nil {
+			t.Fatalf("%s: expected an error", tc.name)
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: error %q does not mention %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestTuningChangesPlay(t *testing.T) {
+	tuning := DefaultTuning()
+	tuning.HazardP = Curve{Base: 1, Min: 1, Max: 1}
+	tuning.BoostP = Curve{}
+	tuning.MaxSpawnsWithoutEdible = 1000
+
+	w := New(Options{Seed: 1, Width: 640, Height: 480, Tuning: &tuning})
+	for i := 0; i < 20; i++ {
+		w.spawnEntityWithDifficulty(0)
+	}
+	for _, e := range w.ents {
+		if e.Kind != KindCircleHazard {
+			t.Fatalf("expected only hazards with hazard_p=1, got kind %v", e.Kind)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package sim
+
+import "image/color"
+
+type Kind int
+
+const (
+	KindSquare Kind = iota
+	KindCircleHazard
+	KindCircleBoost
+)
+
+type Entity struct {
+	Kind Kind
+	X    float64
+	Y    float64
+	Size float64
+	VX   float64
+	VY   float64
+	Col  color.RGBA
+}
+
+// EventKind identifies something notable that happened during a tick.
+type EventKind int
+
+const (
+	EventAte EventKind = iota
+	EventBoost
+	EventDied
+)
+
+// Event is emitted by Step so front ends can react (popups, sounds)
+// without the simulation knowing about them. Entity is the one involved.
+type Event struct {
+	Kind   EventKind
+	Entity Entity
+}
+
+// Cause is what ended a run.
+type Cause int
+
+const (
+	CauseNone Cause = iota
+	CauseSquare
+	CauseHazard
+)
+
+func (c Cause) String() string {
+	switch c {
+	case CauseSquare:
+		return "larger square"
+	case CauseHazard:
+		return "hazard circle"
+	}
+	return "none"
+}
+
+// Result summarizes a run so far.
+type Result struct {
+	Score   int
+	Ticks   int
+	MaxSize float64
+	Cause   Cause
+}
+
+// Input is the player's intent for a single tick.
+type Input struct {
+	// Target is set when X, Y is a position the player snaps to, such as a
+	// cursor. Otherwise MoveX, MoveY steer the player, each in [-1, 1].
+	Target       bool
+	X, Y         float64
+	MoveX, MoveY float64
+
+	// Dash requests a dash. It is ignored while the dash is cooling down.
+	Dash bool
+}
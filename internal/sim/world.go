@@ -0,0 +1,548 @@
+// Package sim holds the rules of Squares: world state, spawning, collision
+// and scoring. It has no rendering or input dependencies, so a full game
+// can run headless, one Step per fixed tick.
+package sim
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Options configure a new World.
+type Options struct {
+	Seed int64
+
+	// Mode is how the player moves. Empty means ModeClassic.
+	Mode Mode
+
+	// Tuning is the balance to play with. Nil means DefaultTuning.
+	Tuning *Tuning
+
+	// Arena size in pixels. The player can go anywhere in it.
+	Width, Height float64
+
+	// ViewWidth and ViewHeight are the size of the camera's view, which
+	// follows the player around an arena bigger than it. Entities spawn
+	// just outside the view and are dropped once well clear of it. Zero
+	// means the view is the whole arena.
+	ViewWidth, ViewHeight float64
+
+	// MinEntities is for stress testing: while fewer entities are alive,
+	// extra ones spawn every tick regardless of the spawn timer.
+	MinEntities int
+}
+
+type World struct {
+	seed int64
+	mode Mode
+	rng  *rand.Rand
+	t    Tuning
+
+	width, height float64
+
+	// The camera's view is viewW by viewH at zoom 1, centered on (camX,
+	// camY). Zooming out shows zoom times as much in each direction.
+	viewW, viewH float64
+	camX, camY   float64
+	zoom         float64
+
+	player Entity
+	angle  float64
+
+	// The last target the player was given. In ModeInertia the player
+	// keeps heading for it until steered otherwise.
+	hasTarget        bool
+	targetX, targetY float64
+	// In ModeClassic the player sits offX, offY from the target, which a
+	// dash sets and which decays back to nothing.
+	offX, offY float64
+
+	score   int
+	over    bool
+	cause   Cause
+	ticks   int
+	maxSize float64
+
+	ents        []Entity
+	spawnTimer  float64
+	minEntities int
+
+	elapsed           float64
+	spawnsSinceEdible int
+
+	dashCDLeft     float64
+	dashInvLeft    float64
+	invincibleLeft float64
+
+	// A dash in progress runs from (dashX, dashY) along the unit vector
+	// (dashDX, dashDY), for another dashLeft seconds.
+	dash           Dash
+	dashLeft       float64
+	dashX, dashY   float64
+	dashDX, dashDY float64
+
+	// Smoothed movement per tick, which dashes follow by default.
+	motionX, motionY float64
+
+	events []Event
+
+	// Per-tick scratch space for collide.
+	broad    grid
+	before   []Entity
+	near     []int
+	contacts []contact
+}
+
+// contact is an entity the player touched this tick, t of the way
+// through it.
+type contact struct {
+	i int
+	t float64
+}
+
+func New(opts Options) *World {
+	w := &World{
+		seed:        opts.Seed,
+		mode:        opts.Mode,
+		rng:         rand.New(rand.NewSource(opts.Seed)),
+		width:       opts.Width,
+		height:      opts.Height,
+		viewW:       opts.ViewWidth,
+		viewH:       opts.ViewHeight,
+		zoom:        1,
+		minEntities: opts.MinEntities,
+	}
+	if w.viewW <= 0 || w.viewH <= 0 {
+		w.viewW, w.viewH = w.width, w.height
+	}
+	if w.mode == "" {
+		w.mode = ModeClassic
+	}
+	if opts.Tuning != nil {
+		w.t = *opts.Tuning
+	} else {
+		w.t = DefaultTuning()
+	}
+	w.player = Entity{
+		Kind: KindSquare,
+		X:    w.width / 2,
+		Y:    w.height / 2,
+		Size: playerStartSize,
+		Col:  color.RGBA{35, 145, 85, 255},
+	}
+	w.maxSize = w.player.Size
+	w.camX, w.camY = w.player.X, w.player.Y
+	w.aim()
+	return w
+}
+
+func (w *World) Tuning() Tuning             { return w.t }
+func (w *World) Seed() int64                { return w.seed }
+func (w *World) Mode() Mode                 { return w.mode }
+func (w *World) Size() (float64, float64)   { return w.width, w.height }
+func (w *World) Camera() (float64, float64) { return w.camX, w.camY }
+func (w *World) Zoom() float64              { return w.zoom }
+func (w *World) Player() Entity             { return w.player }
+func (w *World) Angle() float64             { return w.angle }
+func (w *World) Score() int                 { return w.score }
+func (w *World) Over() bool                 { return w.over }
+func (w *World) Elapsed() float64           { return w.elapsed }
+func (w *World) DashCooldownLeft() float64  { return w.dashCDLeft }
+func (w *World) DashInvLeft() float64       { return w.dashInvLeft }
+func (w *World) InvincibleLeft() float64    { return w.invincibleLeft }
+func (w *World) Dashing() bool              { return w.dashLeft > 0 }
+func (w *World) Ticks() int                 { return w.ticks }
+
+// ViewSize reports how much of the world the camera shows, zoom included.
+func (w *World) ViewSize() (float64, float64) {
+	return w.viewW * w.zoom, w.viewH * w.zoom
+}
+
+// Bounds reports the area the player can move in. It starts as the Width
+// by Height arena at the origin, and widens about its center whenever the
+// camera zooms out past it.
+func (w *World) Bounds() (minX, minY, maxX, maxY float64) {
+	a := w.arena()
+	return a.minX, a.minY, a.maxX, a.maxY
+}
+
+// Result reports the outcome of the run so far.
+func (w *World) Result() Result {
+	return Result{Score: w.score, Ticks: w.ticks, MaxSize: w.maxSize, Cause: w.cause}
+}
+
+// SetTuning swaps the balance mid-run. It takes effect from the next Step.
+func (w *World) SetTuning(t Tuning) {
+	w.t = t
+}
+
+// Entities returns the live entities. The slice is owned by the world and
+// only valid until the next Step.
+func (w *World) Entities() []Entity { return w.ents }
+
+// EntitiesNear appends to dst the index in Entities of every entity whose
+// bounding box comes within r of (x, y), in ascending order.
+func (w *World) EntitiesNear(dst []int, x, y, r float64) []int {
+	return w.broad.queryRadius(dst, x, y, r)
+}
+
+// Events returns what happened during the last Step.
+func (w *World) Events() []Event { return w.events }
+
+// Step advances the world by one tick of TickDT seconds. It does nothing
+// once the game is over.
+func (w *World) Step(in Input) {
+	const dt = TickDT
+
+	w.events = w.events[:0]
+	if w.over {
+		return
+	}
+
+	w.ticks++
+	w.elapsed += dt
+
+	if w.dashCDLeft > 0 {
+		w.dashCDLeft = math.Max(0, w.dashCDLeft-dt)
+	}
+	if w.dashInvLeft > 0 {
+		w.dashInvLeft = math.Max(0, w.dashInvLeft-dt)
+	}
+	if w.invincibleLeft > 0 {
+		w.invincibleLeft = math.Max(0, w.invincibleLeft-dt)
+	}
+
+	fromX, fromY := w.player.X, w.player.Y
+	if in.Dash && w.dashCDLeft <= 0 {
+		w.startDash(in)
+	}
+	switch {
+	case w.dashLeft > 0:
+		w.dashMove(in)
+	case w.mode == ModeInertia:
+		w.drive(in)
+	default:
+		w.move(in)
+	}
+	w.motionX = w.motionX*motionDecay + (w.player.X-fromX)*(1-motionDecay)
+	w.motionY = w.motionY*motionDecay + (w.player.Y-fromY)*(1-motionDecay)
+
+	w.angle += playerRotationRate * dt
+
+	w.zoom += (w.wantZoom() - w.zoom) * zoomFollow
+	w.camX += (w.player.X - w.camX) * cameraFollow
+	w.camY += (w.player.Y - w.camY) * cameraFollow
+	w.aim()
+
+	difficulty := w.t.Difficulty.TimeWeight*w.elapsed + w.t.Difficulty.ScoreWeight*float64(w.score)
+	vw, vh := w.ViewSize()
+	spawnInterval := w.t.SpawnInterval.At(difficulty) / math.Sqrt(vw*vh/referenceArea)
+
+	w.spawnTimer += dt
+	for w.spawnTimer >= spawnInterval {
+		w.spawnTimer -= spawnInterval
+		w.spawnEntityWithDifficulty(difficulty)
+	}
+	for len(w.ents) < w.minEntities {
+		w.spawnEntityWithDifficulty(difficulty)
+	}
+
+	w.collide(dt, fromX, fromY)
+}
+
+// move positions the player for this tick: snapping to a target, less
+// what is left of a dash's offset from it, or steering by the movement
+// vector.
+func (w *World) move(in Input) {
+	w.offX *= dashOffsetDecay
+	w.offY *= dashOffsetDecay
+	if !in.Target {
+		x := w.player.X + clamp(in.MoveX, -1, 1)*w.t.SteerSpeed*w.zoom*TickDT
+		y := w.player.Y + clamp(in.MoveY, -1, 1)*w.t.SteerSpeed*w.zoom*TickDT
+		w.player.X, w.player.Y = w.confine(x, y)
+		return
+	}
+	w.hasTarget = true
+	w.targetX, w.targetY = in.X, in.Y
+	tx, ty := w.confine(in.X, in.Y)
+	w.player.X, w.player.Y = w.confine(tx+w.offX, ty+w.offY)
+	// Walls take up the part of the offset the player cannot have.
+	w.offX, w.offY = w.player.X-tx, w.player.Y-ty
+}
+
+// drive moves the player in ModeInertia: the velocity is pulled toward
+// where the input wants to go, at most Accel per second while steering and
+// Friction per second when coasting to a stop. All three speed up with the
+// zoom, so handling looks the same on screen.
+func (w *World) drive(in Input) {
+	cfg := w.t.Inertia
+	top := cfg.TopSpeed(w.player.Size) * w.zoom
+
+	if in.Target {
+		w.hasTarget = true
+		w.targetX, w.targetY = in.X, in.Y
+	}
+	mx, my := clamp(in.MoveX, -1, 1), clamp(in.MoveY, -1, 1)
+	if mx != 0 || my != 0 {
+		w.hasTarget = false
+	}
+
+	var wantX, wantY float64
+	rate := cfg.Friction * w.zoom
+	switch {
+	case mx != 0 || my != 0:
+		if l := math.Hypot(mx, my); l > 1 {
+			mx, my = mx/l, my/l
+		}
+		wantX, wantY = mx*top, my*top
+		rate = cfg.Accel * w.zoom
+	case w.hasTarget:
+		dx, dy := w.targetX-w.player.X, w.targetY-w.player.Y
+		if d := math.Hypot(dx, dy); d > 0 {
+			speed := math.Min(top, d*cfg.Arrive)
+			wantX, wantY = dx/d*speed, dy/d*speed
+		}
+		rate = cfg.Accel * w.zoom
+	}
+
+	dvx, dvy := wantX-w.player.VX, wantY-w.player.VY
+	if l, most := math.Hypot(dvx, dvy), rate*TickDT; l > most {
+		dvx, dvy = dvx/l*most, dvy/l*most
+	}
+	w.player.VX += dvx
+	w.player.VY += dvy
+	if s := math.Hypot(w.player.VX, w.player.VY); s > top {
+		w.player.VX, w.player.VY = w.player.VX/s*top, w.player.VY/s*top
+	}
+
+	x := w.player.X + w.player.VX*TickDT
+	y := w.player.Y + w.player.VY*TickDT
+	w.player.X, w.player.Y = w.confine(x, y)
+	// Walls stop the player dead along the axis it hit.
+	if w.player.X != x {
+		w.player.VX = 0
+	}
+	if w.player.Y != y {
+		w.player.VY = 0
+	}
+}
+
+// startDash sets off a dash and its cooldown and i-frames. It heads where
+// the player is steering, else the way they have been moving, else toward
+// the target; with nowhere to go the player only gets the i-frames.
+func (w *World) startDash(in Input) {
+	d := w.t.Dash
+	w.dashCDLeft = d.Cooldown
+	w.dashInvLeft = d.IFrames
+
+	dx, dy := clamp(in.MoveX, -1, 1), clamp(in.MoveY, -1, 1)
+	if dx == 0 && dy == 0 && math.Hypot(w.motionX, w.motionY) >= minDashMotion {
+		dx, dy = w.motionX, w.motionY
+	}
+	if dx == 0 && dy == 0 && in.Target {
+		dx, dy = in.X-w.player.X, in.Y-w.player.Y
+	}
+	l := math.Hypot(dx, dy)
+	if l < 1e-9 || d.Distance <= 0 {
+		return
+	}
+
+	w.dash = d
+	w.dash.Distance *= w.zoom
+	w.dashLeft = d.Duration
+	w.dashX, w.dashY = w.player.X, w.player.Y
+	w.dashDX, w.dashDY = dx/l, dy/l
+}
+
+// dashMove places the player along the dash in progress. Input is ignored
+// until it ends, but for noting where the target went. In ModeClassic the
+// player then keeps where the dash put it relative to the target, rather
+// than snapping back under the cursor.
+func (w *World) dashMove(in Input) {
+	w.dashLeft = math.Max(0, w.dashLeft-TickDT)
+	dist := w.dash.Distance * w.dash.Easing.At(1-w.dashLeft/w.dash.Duration)
+	w.player.X, w.player.Y = w.confine(w.dashX+w.dashDX*dist, w.dashY+w.dashDY*dist)
+
+	if w.mode != ModeClassic {
+		return
+	}
+	if in.Target {
+		w.hasTarget = true
+		w.targetX, w.targetY = in.X, in.Y
+	}
+	if w.dashLeft == 0 && w.hasTarget {
+		tx, ty := w.confine(w.targetX, w.targetY)
+		w.offX, w.offY = w.player.X-tx, w.player.Y-ty
+	}
+}
+
+// confine clamps a point to the bounds.
+func (w *World) confine(x, y float64) (float64, float64) {
+	a := w.arena()
+	return clamp(x, a.minX, a.maxX), clamp(y, a.minY, a.maxY)
+}
+
+// playerBox is the player's hitbox: the square as drawn, rotation and
+// all, or a slightly smaller axis-aligned one that forgives near misses.
+func (w *World) playerBox() box {
+	if w.t.Hitbox == HitboxVisual {
+		return box{x: w.player.X, y: w.player.Y, half: w.player.Size / 2, angle: w.angle}
+	}
+	return box{x: w.player.X, y: w.player.Y, half: w.player.Size * forgivingHitboxScale / 2}
+}
+
+// collide moves every entity and resolves its contacts with the player,
+// who moved from (fromX, fromY) this tick. Both paths are swept, so nothing
+// fast slips through, and contacts are resolved in the order they happened:
+// a boost picked up first protects from the threat behind it, and nothing
+// after a death counts.
+func (w *World) collide(dt, fromX, fromY float64) {
+	to := w.playerBox()
+	from := to
+	from.x, from.y = fromX, fromY
+
+	live := w.view().grow(cullMargin * w.zoom)
+	w.before = w.before[:0]
+	alive := w.ents[:0]
+	maxMove := 0.0
+	for _, e := range w.ents {
+		start := e
+		e.X += e.VX * dt
+		e.Y += e.VY * dt
+
+		if !live.contains(e.X, e.Y) {
+			continue
+		}
+
+		maxMove = math.Max(maxMove, math.Max(math.Abs(e.X-start.X), math.Abs(e.Y-start.Y)))
+		w.before = append(w.before, start)
+		alive = append(alive, e)
+	}
+	w.ents = alive
+	w.index()
+
+	// Only entities near the player's path can touch it. Searching around
+	// where they ended up, the path is widened by the furthest any of them
+	// moved, and by the player's corners should it be rotated.
+	reach := aabb{
+		minX: math.Min(from.x, to.x), minY: math.Min(from.y, to.y),
+		maxX: math.Max(from.x, to.x), maxY: math.Max(from.y, to.y),
+	}.grow(to.half*math.Sqrt2 + maxMove)
+	w.near = w.broad.query(w.near[:0], reach)
+
+	w.contacts = w.contacts[:0]
+	for _, i := range w.near {
+		if t, ok := sweptContact(from, to, w.before[i], w.ents[i]); ok {
+			w.contacts = append(w.contacts, contact{i: i, t: t})
+		}
+	}
+
+	sort.SliceStable(w.contacts, func(a, b int) bool { return w.contacts[a].t < w.contacts[b].t })
+
+	var gone []int
+	for _, c := range w.contacts {
+		if w.over {
+			break
+		}
+		e := w.ents[c.i]
+		inv := (w.invincibleLeft > 0) || (w.dashInvLeft > 0)
+
+		switch e.Kind {
+		case KindSquare:
+			if inv || w.player.Size > e.Size {
+				w.score++
+				w.player.Size += w.t.GrowthScale*e.Size + w.t.GrowthFlat
+				w.maxSize = math.Max(w.maxSize, w.player.Size)
+				w.spawnsSinceEdible = 0
+				w.events = append(w.events, Event{Kind: EventAte, Entity: e})
+				gone = append(gone, c.i)
+				continue
+			}
+			w.die(e)
+
+		case KindCircleHazard:
+			w.die(e)
+
+		case KindCircleBoost:
+			w.invincibleLeft = w.t.InvincibleDuration
+			w.dashInvLeft = 0
+			w.events = append(w.events, Event{Kind: EventBoost, Entity: e})
+			gone = append(gone, c.i)
+		}
+	}
+
+	if len(gone) > 0 {
+		sort.Ints(gone)
+		alive := w.ents[:0]
+		for i, e := range w.ents {
+			if len(gone) > 0 && gone[0] == i {
+				gone = gone[1:]
+				continue
+			}
+			alive = append(alive, e)
+		}
+		w.ents = alive
+		w.index()
+	}
+}
+
+// index rebuilds the broadphase over the live entities.
+func (w *World) index() {
+	w.broad.reset(w.view().grow(cullMargin * w.zoom))
+	for _, e := range w.ents {
+		w.broad.insert(boundsOf(e))
+	}
+}
+
+// wantZoom is the zoom that keeps the player zoomFraction of the view's
+// shorter side, or 1 while it is smaller than that.
+func (w *World) wantZoom() float64 {
+	return math.Max(1, w.player.Size/(zoomFraction*math.Min(w.viewW, w.viewH)))
+}
+
+// arena is the area the player can move in; see Bounds.
+func (w *World) arena() aabb {
+	vw, vh := w.ViewSize()
+	hw, hh := math.Max(w.width, vw)/2, math.Max(w.height, vh)/2
+	return aabb{w.width/2 - hw, w.height/2 - hh, w.width/2 + hw, w.height/2 + hh}
+}
+
+// aim keeps the camera's view inside the arena, centering it on any side
+// where the arena is no bigger than the view.
+func (w *World) aim() {
+	a := w.arena()
+	vw, vh := w.ViewSize()
+	w.camX = centerIn(w.camX, vw, a.minX, a.maxX)
+	w.camY = centerIn(w.camY, vh, a.minY, a.maxY)
+}
+
+func centerIn(c, view, lo, hi float64) float64 {
+	if view >= hi-lo {
+		return (lo + hi) / 2
+	}
+	return clamp(c, lo+view/2, hi-view/2)
+}
+
+// view is the part of the world the camera sees.
+func (w *World) view() aabb {
+	vw, vh := w.ViewSize()
+	return aabb{
+		minX: w.camX - vw/2, minY: w.camY - vh/2,
+		maxX: w.camX + vw/2, maxY: w.camY + vh/2,
+	}
+}
+
+func (w *World) die(by Entity) {
+	if w.over {
+		return
+	}
+	w.over = true
+	if by.Kind == KindCircleHazard {
+		w.cause = CauseHazard
+	} else {
+		w.cause = CauseSquare
+	}
+	w.events = append(w.events, Event{Kind: EventDied, Entity: by})
+}
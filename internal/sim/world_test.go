@@ -0,0 +1,374 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func newTestWorld(seed int64) *World {
+	return New(Options{Seed: seed, Width: 640, Height: 480})
+}
+
+// circleInput steers the player around the arena center.
+func circleInput(tick int) Input {
+	a := float64(tick) * 0.02
+	return Input{
+		Target: true,
+		X:      320 + 150*math.Cos(a),
+		Y:      240 + 110*math.Sin(a),
+		Dash:   tick%90 == 0,
+	}
+}
+
+func TestWorldDeterministic(t *testing.T) {
+	a := newTestWorld(7)
+	b := newTestWorld(7)
+
+	for i := 0; i < 3000; i++ {
+		a.Step(circleInput(i))
+		b.Step(circleInput(i))
+	}
+
+	if a.Score() != b.Score() || a.Over() != b.Over() || a.Elapsed() != b.Elapsed() {
+		t.Fatalf("runs diverged: score %d/%d over %v/%v elapsed %v/%v",
+			a.Score(), b.Score(), a.Over(), b.Over(), a.Elapsed(), b.Elapsed())
+	}
+	if a.Player() != b.Player() {
+		t.Fatalf("player diverged: %+v vs %+v", a.Player(), b.Player())
+	}
+	if len(a.Entities()) != len(b.Entities()) {
+		t.Fatalf("entity count diverged: %d vs %d", len(a.Entities()), len(b.Entities()))
+	}
+	for i := range a.Entities() {
+		if a.Entities()[i] != b.Entities()[i] {
+			t.Fatalf("entity %d diverged", i)
+		}
+	}
+}
+
+func TestWorldSpawnRateFollowsArena(t *testing.T) {
+	spawned := func(w, h float64) int {
+		world := New(Options{Seed: 3, Width: w, Height: h})
+		for i := 0; i < 90; i++ {
+			world.Step(Input{Target: true, X: w / 2, Y: h / 2})
+		}
+		return len(world.Entities())
+	}
+	small, normal, big := spawned(320, 240), spawned(640, 480), spawned(1280, 960)
+	if !(small < normal && normal < big) {
+		t.Fatalf("entities after 1.5s: %d in 320x240, %d in 640x480, %d in 1280x960; want increasing", small, normal, big)
+	}
+}
+
+func TestWorldCameraFollowsPlayer(t *testing.T) {
+	w := New(Options{Seed: 1, Width: 2560, Height: 1920, ViewWidth: 640, ViewHeight: 480})
+	if x, y := w.Camera(); x != 1280 || y != 960 {
+		t.Fatalf("camera starts at (%v, %v), want the player at (1280, 960)", x, y)
+	}
+
+	w.Step(Input{Target: true, X: 1600, Y: 960})
+	if x, _ := w.Camera(); x <= 1280 || x >= 1600 {
+		t.Fatalf("camera x = %v after one tick, want it partway to the player at 1600", x)
+	}
+
+	// Into the corner: the view stops at the arena's edge.
+	for i := 0; i < 120 && !w.Over(); i++ {
+		w.Step(Input{Target: true, X: 2560, Y: 1920})
+	}
+	if x, y := w.Camera(); math.Abs(x-2240) > 1e-3 || math.Abs(y-1680) > 1e-3 {
+		t.Fatalf("camera at (%v, %v), want it clamped to (2240, 1680)", x, y)
+	}
+	for _, e := range w.Entities() {
+		if e.X < 1920-cullMargin || e.Y < 1440-cullMargin {
+			t.Fatalf("entity at (%v, %v) is far outside the view", e.X, e.Y)
+		}
+	}
+}
+
+func TestWorldCameraFixedWithoutView(t *testing.T) {
+	w := newTestWorld(1)
+	for i := 0; i < 30; i++ {
+		w.Step(Input{Target: true, X: 10, Y: 10})
+	}
+	if x, y := w.Camera(); x != 320 || y != 240 {
+		t.Fatalf("camera at (%v, %v), want the arena center", x, y)
+	}
+}
+
+func TestWorldZoomsOutAsPlayerGrows(t *testing.T) {
+	w := newTestWorld(1)
+	w.Step(Input{})
+	if w.Zoom() != 1 {
+		t.Fatalf("zoom = %v for a starting player, want 1", w.Zoom())
+	}
+
+	// Twice the size that fits 10% of the 480px side.
+	w.player.Size = 96
+	for i := 0; i < 200; i++ {
+		w.ents = w.ents[:0] // nothing to eat
+		w.Step(Input{Target: true, X: 320, Y: 240})
+	}
+	if math.Abs(w.Zoom()-2) > 1e-3 {
+		t.Fatalf("zoom = %v, want 2", w.Zoom())
+	}
+	if vw, vh := w.ViewSize(); math.Abs(vw-1280) > 1 || math.Abs(vh-960) > 1 {
+		t.Fatalf("view is %vx%v, want 1280x960", vw, vh)
+	}
+
+	// The arena widens with the view, so there is room to move.
+	x0, y0, _, _ := w.Bounds()
+	if math.Abs(x0+320) > 1 || math.Abs(y0+240) > 1 {
+		t.Fatalf("bounds start at (%v, %v), want (-320, -240)", x0, y0)
+	}
+	w.ents = w.ents[:0]
+	w.Step(Input{Target: true, X: -300, Y: -200})
+	if p := w.Player(); p.X != -300 || p.Y != -200 {
+		t.Fatalf("player at (%v, %v), want it moved past the original arena to (-300, -200)", p.X, p.Y)
+	}
+}
+
+func TestWorldEatsSmallerSquare(t *testing.T) {
+	w := newTestWorld(1)
+	p := w.Player()
+	w.ents = append(w.ents, Entity{Kind: KindSquare, X: p.X, Y: p.Y, Size: p.Size / 2})
+
+	w.Step(Input{Target: true, X: p.X, Y: p.Y})
+
+	if w.Score() != 1 {
+		t.Fatalf("expected score 1, got %d", w.Score())
+	}
+	if w.Player().Size <= p.Size {
+		t.Fatalf("expected player to grow from %v, got %v", p.Size, w.Player().Size)
+	}
+	if ev := w.Events(); len(ev) == 0 || ev[0].Kind != EventAte {
+		t.Fatalf("expected an EventAte, got %+v", ev)
+	}
+}
+
+func TestWorldDiesOnHazard(t *testing.T) {
+	w := newTestWorld(1)
+	p := w.Player()
+	w.ents = append(w.ents, Entity{Kind: KindCircleHazard, X: p.X, Y: p.Y, Size: 20})
+
+	w.Step(Input{Target: true, X: p.X, Y: p.Y})
+
+	if !w.Over() {
+		t.Fatalf("expected hazard contact to end the game")
+	}
+	if r := w.Result(); r.Cause != CauseHazard || r.Ticks != 1 {
+		t.Fatalf("expected hazard death on tick 1, got %+v", r)
+	}
+
+	// Once over, the world is frozen.
+	elapsed := w.Elapsed()
+	w.Step(Input{Target: true, X: p.X, Y: p.Y})
+	if w.Elapsed() != elapsed {
+		t.Fatalf("expected no progress after game over")
+	}
+}
+
+func TestWorldSteersWithoutTarget(t *testing.T) {
+	w := newTestWorld(1)
+	p := w.Player()
+
+	w.Step(Input{MoveX: 1})
+	if got := w.Player().X - p.X; math.Abs(got-w.t.SteerSpeed*TickDT) > 1e-9 {
+		t.Fatalf("expected to move %v right, moved %v", w.t.SteerSpeed*TickDT, got)
+	}
+
+	// No target and no movement keeps the player in place.
+	q := w.Player()
+	w.Step(Input{})
+	if w.Player().X != q.X || w.Player().Y != q.Y {
+		t.Fatalf("expected player to stay put, moved to (%v,%v)", w.Player().X, w.Player().Y)
+	}
+}
+
+func TestWorldSweepsFastMoves(t *testing.T) {
+	w := newTestWorld(1)
+	p := w.Player()
+	w.ents = append(w.ents, Entity{Kind: KindCircleHazard, X: p.X + 100, Y: p.Y, Size: 20})
+
+	// A single-tick flick clean over the hazard still hits it.
+	w.Step(Input{Target: true, X: p.X + 200, Y: p.Y})
+
+	if r := w.Result(); !w.Over() || r.Cause != CauseHazard {
+		t.Fatalf("expected flicking through a hazard to kill, got %+v", r)
+	}
+}
+
+func TestWorldResolvesEarliestContactFirst(t *testing.T) {
+	w := newTestWorld(1)
+	p := w.Player()
+	// Food is listed first but lies behind the hazard on the player's path.
+	w.ents = append(w.ents,
+		Entity{Kind: KindSquare, X: p.X + 150, Y: p.Y, Size: 8},
+		Entity{Kind: KindCircleHazard, X: p.X + 60, Y: p.Y, Size: 20},
+	)
+
+	w.Step(Input{Target: true, X: p.X + 200, Y: p.Y})
+
+	if !w.Over() || w.Score() != 0 {
+		t.Fatalf("expected death before reaching the food, got over=%v score=%d", w.Over(), w.Score())
+	}
+	if ev := w.Events(); len(ev) != 1 || ev[0].Kind != EventDied {
+		t.Fatalf("expected only a death event, got %+v", ev)
+	}
+}
+
+func BenchmarkStepStress(b *testing.B) {
+	w := New(Options{Seed: 1, Width: 640, Height: 480, MinEntities: 3000})
+	for b.Loop() {
+		// Keep the player alive and small so every tick does full work.
+		w.invincibleLeft = 1
+		w.player.Size = 22
+		w.Step(Input{Target: true, X: 320, Y: 240})
+	}
+}
+
+func TestWorldDashMovesPlayer(t *testing.T) {
+	w := newTestWorld(1)
+	d := w.t.Dash
+	p := w.Player()
+
+	w.Step(Input{MoveX: 1, Dash: true})
+	if !w.Dashing() || w.DashInvLeft() != d.IFrames || w.DashCooldownLeft() != d.Cooldown {
+		t.Fatalf("expected a dash to start, dashing=%v inv=%v cd=%v", w.Dashing(), w.DashInvLeft(), w.DashCooldownLeft())
+	}
+
+	// Steering is ignored until the dash ends, exactly Distance away.
+	for w.Dashing() {
+		w.Step(Input{MoveY: 1})
+	}
+	q := w.Player()
+	if math.Abs(q.X-p.X-d.Distance) > 1e-9 || q.Y != p.Y {
+		t.Fatalf("expected to end %v right of (%v,%v), got (%v,%v)", d.Distance, p.X, p.Y, q.X, q.Y)
+	}
+
+	// A second dash waits for the cooldown.
+	w.Step(Input{MoveX: -1, Dash: true})
+	if w.Dashing() {
+		t.Fatalf("expected no dash while cooling down")
+	}
+}
+
+func TestWorldDashFollowsRecentMotion(t *testing.T) {
+	w := newTestWorld(1)
+	for range 10 {
+		w.Step(Input{MoveY: -1})
+	}
+	p := w.Player()
+
+	// With no steering, a dash keeps going the way the player was.
+	w.Step(Input{Dash: true})
+	if q := w.Player(); q.Y >= p.Y || math.Abs(q.X-p.X) > 1e-9 {
+		t.Fatalf("expected to dash up from (%v,%v), got (%v,%v)", p.X, p.Y, q.X, q.Y)
+	}
+}
+
+func TestWorldDashOutlastsCursor(t *testing.T) {
+	w := newTestWorld(1)
+	d := w.t.Dash
+	w.Step(Input{Target: true, X: 330, Y: 240})
+	w.Step(Input{Target: true, X: 340, Y: 240})
+	p := w.Player()
+
+	// The cursor is still, so the dash follows the last movement: right.
+	w.Step(Input{Dash: true})
+	for w.Dashing() {
+		w.Step(Input{})
+	}
+	w.Step(Input{Target: true, X: 345, Y: 240})
+	q := w.Player()
+	if q.X-p.X < d.Distance/2 {
+		t.Fatalf("expected the player to stay dashed away from %v after the cursor moved, got %v", p.X, q.X)
+	}
+
+	// Then it drifts back toward the cursor.
+	for range 60 {
+		w.Step(Input{Target: true, X: 345, Y: 240})
+	}
+	if r := w.Player(); w.Over() || r.X-345 >= (q.X-345)/2 {
+		t.Fatalf("expected the player a second later to be at least halfway back from %v to the cursor, got %v (over=%v)", q.X, r.X, w.Over())
+	}
+}
+
+func TestWorldDashWithoutDirection(t *testing.T) {
+	w := newTestWorld(1)
+	p := w.Player()
+
+	w.Step(Input{Dash: true})
+	if w.Dashing() || w.DashInvLeft() <= 0 || w.Player() != p {
+		t.Fatalf("expected i-frames without movement, dashing=%v inv=%v", w.Dashing(), w.DashInvLeft())
+	}
+}
+
+func TestWorldInertiaAccelerates(t *testing.T) {
+	w := New(Options{Seed: 1, Width: 640, Height: 480, Mode: ModeInertia})
+	cfg := w.t.Inertia
+	top := cfg.TopSpeed(w.Player().Size)
+
+	w.Step(Input{MoveX: 1})
+	if got, want := w.Player().VX, cfg.Accel*TickDT; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("expected one tick of acceleration (%v), got %v", want, got)
+	}
+
+	for range 20 {
+		w.Step(Input{MoveX: 1})
+	}
+	if got := w.Player().VX; math.Abs(got-top) > 1e-9 {
+		t.Fatalf("expected to reach the top speed %v, got %v", top, got)
+	}
+
+	// Letting go coasts to a stop rather than stopping dead.
+	w.Step(Input{})
+	if vx := w.Player().VX; vx <= 0 || vx >= top {
+		t.Fatalf("expected to slow down gradually, got %v", vx)
+	}
+	for range 60 {
+		w.Step(Input{})
+	}
+	if p := w.Player(); p.VX != 0 || p.VY != 0 {
+		t.Fatalf("expected to come to a stop, still moving at (%v,%v)", p.VX, p.VY)
+	}
+}
+
+func TestWorldInertiaChasesLastTarget(t *testing.T) {
+	w := New(Options{Seed: 1, Width: 640, Height: 480, Mode: ModeInertia})
+
+	// The cursor moves once, then stays put and stops reporting.
+	w.Step(Input{Target: true, X: 420, Y: 240})
+	for range 120 {
+		w.Step(Input{})
+	}
+	if p := w.Player(); math.Abs(p.X-420) > 1 || math.Abs(p.Y-240) > 1e-9 {
+		t.Fatalf("expected to settle at the target, got (%v,%v)", p.X, p.Y)
+	}
+}
+
+func TestInertiaTopSpeedShrinks(t *testing.T) {
+	cfg := DefaultTuning().Inertia
+	if got := cfg.TopSpeed(playerStartSize); got != cfg.MaxSpeed {
+		t.Fatalf("expected max_speed at the start size, got %v", got)
+	}
+	if cfg.TopSpeed(80) >= cfg.TopSpeed(40) {
+		t.Fatalf("expected bigger players to be slower")
+	}
+	if got := cfg.TopSpeed(1e6); got != cfg.MinSpeed {
+		t.Fatalf("expected the speed to bottom out at %v, got %v", cfg.MinSpeed, got)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, m := range Modes() {
+		if got, err := ParseMode(string(m)); err != nil || got != m {
+			t.Fatalf("ParseMode(%q) = %q, %v", m, got, err)
+		}
+	}
+	if got, err := ParseMode(""); err != nil || got != ModeClassic {
+		t.Fatalf("expected the empty mode to be classic, got %q, %v", got, err)
+	}
+	if _, err := ParseMode("zen"); err == nil {
+		t.Fatalf("expected an unknown mode to be rejected")
+	}
+}
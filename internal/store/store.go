@@ -0,0 +1,93 @@
+// Package store reads and writes the small versioned JSON files the game
+// keeps in the user's config directory, such as the settings and the high
+// scores, so that a crash or a damaged file never costs more than it has
+// to.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// File is a JSON file whose top-level object has a "version" field.
+type File struct {
+	Path string
+	// Name starts the errors Load makes itself, such as "settings".
+	Name string
+	// Version is the format version this build writes, and the newest it
+	// reads.
+	Version int
+	// Corrupt is wrapped by the error Load reports for a file that cannot
+	// be read.
+	Corrupt error
+}
+
+// Load reads the file into v, leaving v alone if there is no file. A
+// file that cannot be read as v, or that has no version, is moved aside
+// to Path+".corrupt" and Load returns an error wrapping Corrupt; v may
+// have been partly filled in. A file from a newer version is an error
+// and is left alone.
+func (f File) Load(v any) error {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var head struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil || head.Version < 1 {
+		return f.moveAside()
+	}
+	if head.Version > f.Version {
+		return fmt.Errorf("%s: %s has format version %d; this build reads up to %d", f.Name, f.Path, head.Version, f.Version)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return f.moveAside()
+	}
+	return nil
+}
+
+// moveAside renames a corrupt file out of the way so the next save does
+// not overwrite it.
+func (f File) moveAside() error {
+	if err := os.Rename(f.Path, f.Path+".corrupt"); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s moved to %s.corrupt", f.Corrupt, f.Path, filepath.Base(f.Path))
+}
+
+// Save writes v to the file through a temporary file renamed into place,
+// so a crash mid-write leaves the previous contents intact. v should
+// carry the version itself.
+func (f File) Save(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(f.Path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var errBad = errors.New("test: corrupt file")
+
+type doc struct {
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+func testFile(t *testing.T) File {
+	return File{Path: filepath.Join(t.TempDir(), "sub", "doc.json"), Name: "test", Version: 2, Corrupt: errBad}
+}
+
+func TestSaveLoad(t *testing.T) {
+	f := testFile(t)
+	d := doc{Text: "untouched"}
+	if err := f.Load(&d); err != nil || d.Text != "untouched" {
+		t.Fatalf("missing file: %+v, %v", d, err)
+	}
+	if err := f.Save(doc{Version: 2, Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Load(&d); err != nil || d != (doc{Version: 2, Text: "hi"}) {
+		t.Fatalf("loaded %+v, %v", d, err)
+	}
+	left, _ := filepath.Glob(filepath.Join(filepath.Dir(f.Path), ".*"))
+	if len(left) != 0 {
+		t.Fatalf("temporary files left behind: %v", left)
+	}
+}
+
+func TestLoadMovesCorruptAside(t *testing.T) {
+	for _, data := range []string{"{not json", `{"text": "no version"}`, `{"version": 1, "text": 5}`} {
+		f := testFile(t)
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f.Path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var d doc
+		if err := f.Load(&d); !errors.Is(err, errBad) {
+			t.Fatalf("%s: err = %v, want %v", data, err, errBad)
+		}
+		if _, err := os.Stat(f.Path + ".corrupt"); err != nil {
+			t.Fatalf("%s: not moved aside: %v", data, err)
+		}
+	}
+}
+
+func TestLoadRefusesNewer(t *testing.T) {
+	f := testFile(t)
+	if err := f.Save(doc{Version: 3}); err != nil {
+		t.Fatal(err)
+	}
+	var d doc
+	if err := f.Load(&d); err == nil || errors.Is(err, errBad) {
+		t.Fatalf("err = %v, want a version error", err)
+	}
+	if _, err := os.Stat(f.Path); err != nil {
+		t.Fatalf("newer file moved: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package ui
+
+// Inset shrinks r by d on every side.
+func (r Rect) Inset(d float64) Rect {
+	return Rect{X: r.X + d, Y: r.Y + d, W: max(0, r.W-2*d), H: max(0, r.H-2*d)}
+}
+
+// Columns splits r into n side-by-side columns, gap apart.
+func (r Rect) Columns(n int, gap float64) []Rect {
+	w := (r.W - gap*float64(n-1)) / float64(n)
+	cols := make([]Rect, n)
+	for i := range cols {
+		cols[i] = Rect{X: r.X + float64(i)*(w+gap), Y: r.Y, W: w, H: r.H}
+	}
+	return cols
+}
+
+// Centered is a w by h rectangle centered in r.
+func Centered(r Rect, w, h float64) Rect {
+	return Rect{X: r.X + (r.W-w)/2, Y: r.Y + (r.H-h)/2, W: w, H: h}
+}
+
+// Stack hands out the rows of a rectangle from the top down.
+type Stack struct {
+	r   Rect
+	y   float64
+	gap float64
+}
+
+// VStack stacks rows in r, gap apart.
+func VStack(r Rect, gap float64) *Stack {
+	return &Stack{r: r, y: r.Y, gap: gap}
+}
+
+// Row takes the next h tall row.
+func (s *Stack) Row(h float64) Rect {
+	row := Rect{X: s.r.X, Y: s.y, W: s.r.W, H: h}
+	s.y += h + s.gap
+	return row
+}
+
+// Space skips h without a row.
+func (s *Stack) Space(h float64) {
+	s.y += h
+}
+
+// Rest is whatever of the rectangle is left.
+func (s *Stack) Rest() Rect {
+	return Rect{X: s.r.X, Y: s.y, W: s.r.W, H: max(0, s.r.Y+s.r.H-s.y)}
+}
+
+// StackHeight is how tall n rows of height h are, gap apart, for centering
+// a stack before laying it out.
+func StackHeight(n int, h, gap float64) float64 {
+	if n <= 0 {
+		return 0
+	}
+	return float64(n)*h + float64(n-1)*gap
+}
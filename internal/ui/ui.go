@@ -0,0 +1,319 @@
+// Package ui is a small immediate-mode widget kit for the game's menus.
+//
+// Every frame, a scene calls Begin, then one function per widget in the
+// order they should be navigated, then End. Widget functions report what
+// the player did to them this frame and record how they look; Draw replays
+// that recording through a Painter. Recording in Update and drawing in
+// Draw keeps the kit independent of Ebiten, so it runs headless in tests.
+//
+// Widgets are named by an ID unique within the frame. The pointer focuses
+// whatever it moves over; Up and Down move focus through the widgets in
+// call order. Widgets that need Up and Down for themselves, such as an
+// open dropdown, grab navigation until they are done.
+package ui
+
+import (
+	"image/color"
+	"slices"
+
+	"golang.org/x/image/font"
+
+	"github.com/jdefrancesco/squares/internal/input"
+)
+
+// Rect is an axis-aligned rectangle in logical pixels.
+type Rect struct {
+	X, Y, W, H float64
+}
+
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+// Painter draws what the widgets recorded.
+type Painter interface {
+	FillRect(r Rect, c color.Color)
+	// Text draws s in the kit's face, scale times its size, with its
+	// top-left corner at (x, y).
+	Text(s string, x, y, scale float64, c color.Color)
+}
+
+// Input is one frame of input for the widgets.
+type Input struct {
+	input.Frame
+
+	// Chars is the text typed this frame.
+	Chars []rune
+	// Enter and Erase are set on the frame Enter or Backspace went down,
+	// for text input, where Confirm and Back are bound to too much.
+	Enter, Erase bool
+	// Scroll is how far the mouse wheel turned this frame, positive up.
+	Scroll float64
+}
+
+// Style is how widgets look.
+type Style struct {
+	Text    color.RGBA
+	TextDim color.RGBA
+	Panel   color.RGBA
+	Item    color.RGBA
+	Focus   color.RGBA
+	Track   color.RGBA
+	Shadow  color.RGBA
+	// Popup is behind an open dropdown, over the widgets below it.
+	Popup color.RGBA
+
+	// Pad is the space between a widget's edge and its contents.
+	Pad float64
+
+	// On and Off are what a Toggle says it is.
+	On, Off string
+}
+
+// DefaultStyle is light text on dark translucent boxes, with the player's
+// green marking focus.
+var DefaultStyle = Style{
+	Text:    color.RGBA{235, 235, 235, 255},
+	TextDim: color.RGBA{170, 170, 170, 255},
+	Panel:   color.RGBA{0, 0, 0, 110},
+	Item:    color.RGBA{20, 20, 20, 150},
+	Focus:   color.RGBA{35, 145, 85, 220},
+	Track:   color.RGBA{90, 90, 90, 200},
+	Shadow:  color.RGBA{0, 0, 0, 90},
+	Popup:   color.RGBA{25, 25, 25, 240},
+	Pad:     8,
+	On:      "On",
+	Off:     "Off",
+}
+
+type cmd struct {
+	text  string
+	r     Rect
+	scale float64
+	col   color.Color
+}
+
+// Context holds the state widgets keep between frames: focus, grabs,
+// scroll positions. One Context serves one screen at a time.
+type Context struct {
+	Style Style
+	face  font.Face
+
+	in     Input
+	used   input.Action
+	screen Rect
+
+	cmds    []cmd
+	overlay []cmd
+
+	focus       string
+	order, prev []string
+
+	// The pointer moved this frame.
+	moved   bool
+	px, py  float64
+	pointed bool
+
+	// grab has taken Up and Down from focus navigation, and typing is set
+	// if it is a text input taking keys too. drag follows the pointer
+	// while it is held down.
+	grab   string
+	typing bool
+	drag   string
+	// busy is set on frames a widget had or took a grab.
+	busy bool
+
+	lists map[string]*listState
+	drops map[string]int
+}
+
+func New(face font.Face) *Context {
+	return &Context{
+		Style: DefaultStyle,
+		face:  face,
+		lists: map[string]*listState{},
+		drops: map[string]int{},
+	}
+}
+
+// Reset forgets focus, grabs and everything recorded, for a new screen.
+func (c *Context) Reset() {
+	c.cmds, c.overlay = c.cmds[:0], c.overlay[:0]
+	c.order, c.prev = c.order[:0], c.prev[:0]
+	c.focus, c.grab, c.drag = "", "", ""
+	c.typing = false
+	clear(c.lists)
+	clear(c.drops)
+}
+
+// Begin starts a frame on a screen of the given size.
+func (c *Context) Begin(in Input, screen Rect) {
+	c.in = in
+	c.used = 0
+	c.busy = c.grab != ""
+	c.screen = screen
+	c.cmds, c.overlay = c.cmds[:0], c.overlay[:0]
+	c.prev, c.order = c.order, c.prev[:0]
+
+	c.moved = in.Pointer && (!c.pointed || in.PointerX != c.px || in.PointerY != c.py)
+	if in.Pointer {
+		c.px, c.py, c.pointed = in.PointerX, in.PointerY, true
+	}
+	if !in.Held.Has(input.Click) {
+		c.drag = ""
+	}
+
+	if c.grab != "" || len(c.prev) == 0 {
+		return
+	}
+	n := len(c.prev)
+	i := slices.Index(c.prev, c.focus)
+	switch {
+	case c.take(input.Up):
+		if i < 0 {
+			i = n
+		}
+		c.focus = c.prev[(i+n-1)%n]
+	case c.take(input.Down):
+		c.focus = c.prev[(i+1)%n]
+	}
+}
+
+// End finishes the frame. A grab by a widget that was not drawn lapses.
+func (c *Context) End() {
+	if c.grab != "" && !slices.Contains(c.order, c.grab) {
+		c.release()
+	}
+	if c.focus != "" && len(c.order) > 0 && !slices.Contains(c.order, c.focus) {
+		c.focus = c.order[0]
+	}
+	c.cmds = append(c.cmds, c.overlay...)
+	c.overlay = c.overlay[:0]
+}
+
+// Draw replays the last frame's widgets.
+func (c *Context) Draw(p Painter) {
+	for _, d := range c.cmds {
+		if d.text == "" {
+			p.FillRect(d.r, d.col)
+		} else {
+			p.Text(d.text, d.r.X, d.r.Y, d.scale, d.col)
+		}
+	}
+}
+
+// Screen is the screen passed to Begin.
+func (c *Context) Screen() Rect {
+	return c.screen
+}
+
+// Focus reports the focused widget.
+func (c *Context) Focus() string {
+	return c.focus
+}
+
+// SetFocus focuses the widget id.
+func (c *Context) SetFocus(id string) {
+	c.focus = id
+}
+
+// Typing reports whether a text input is taking keys, so the caller knows
+// not to treat them as shortcuts.
+func (c *Context) Typing() bool {
+	return c.typing
+}
+
+// hold has widget id take Up and Down, and all keys if it is typing.
+func (c *Context) hold(id string, typing bool) {
+	c.grab, c.typing = id, typing
+	c.focus = id
+	c.busy = true
+}
+
+func (c *Context) release() {
+	c.grab, c.typing = "", false
+}
+
+// Pressed reports whether a went down this frame and no widget used it,
+// for scene-wide shortcuts such as Back. Nothing is left over on a frame
+// a widget grabbed input, so the Escape that closes a dropdown does not
+// also leave the screen.
+func (c *Context) Pressed(a input.Action) bool {
+	return c.in.Pressed.Has(a) && c.used&a == 0 && !c.busy
+}
+
+// take uses up a press of a, reporting whether there was one to use.
+func (c *Context) take(a input.Action) bool {
+	if !c.in.Pressed.Has(a) || c.used&a != 0 {
+		return false
+	}
+	c.used |= a
+	return true
+}
+
+// RowHeight is the height of a one-line widget.
+func (c *Context) RowHeight() float64 {
+	return c.lineHeight() + c.Style.Pad
+}
+
+func (c *Context) lineHeight() float64 {
+	return float64(c.face.Metrics().Height.Ceil())
+}
+
+// TextWidth is how wide s draws at scale 1.
+func (c *Context) TextWidth(s string) float64 {
+	return float64(font.MeasureString(c.face, s).Ceil())
+}
+
+func (c *Context) fill(r Rect, col color.Color) {
+	c.cmds = append(c.cmds, cmd{r: r, col: col})
+}
+
+func (c *Context) text(s string, x, y, scale float64, col color.Color) {
+	if s != "" {
+		c.cmds = append(c.cmds, cmd{text: s, r: Rect{X: x, Y: y}, scale: scale, col: col})
+	}
+}
+
+// textIn draws s vertically centered in r: at its left plus the padding,
+// or centered if center is set.
+func (c *Context) textIn(r Rect, s string, center bool, col color.Color) {
+	x := r.X + c.Style.Pad
+	if center {
+		x = r.X + (r.W-c.TextWidth(s))/2
+	}
+	c.text(s, x, r.Y+(r.H-c.lineHeight())/2, 1, col)
+}
+
+// focusable registers widget id at r for navigation and reports whether
+// it has focus and whether the pointer is over it.
+func (c *Context) focusable(id string, r Rect) (focused, hovered bool) {
+	c.order = append(c.order, id)
+	free := c.grab == "" || c.grab == id
+	hovered = free && c.pointed && r.Contains(c.px, c.py)
+	if hovered && c.moved && c.grab == "" {
+		c.focus = id
+	}
+	if c.focus == "" {
+		c.focus = id
+	}
+	return c.focus == id, hovered
+}
+
+// activated reports whether widget id was clicked or confirmed this frame.
+func (c *Context) activated(id string, focused, hovered bool) bool {
+	if hovered && c.take(input.Click) {
+		c.focus = id
+		return true
+	}
+	return focused && c.take(input.Confirm)
+}
+
+// box draws a widget's background.
+func (c *Context) box(r Rect, focused bool) {
+	if focused {
+		c.fill(r, c.Style.Focus)
+	} else {
+		c.fill(r, c.Style.Item)
+	}
+}
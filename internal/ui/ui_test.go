@@ -0,0 +1,292 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+
+	"github.com/jdefrancesco/squares/internal/input"
+)
+
+var screen = Rect{W: 640, H: 480}
+
+// run plays one frame of widgets.
+func run(c *Context, in Input, widgets func()) {
+	c.Begin(in, screen)
+	widgets()
+	c.End()
+}
+
+func press(a input.Action) Input {
+	return Input{Frame: input.Frame{Pressed: a, State: input.State{Held: a}}}
+}
+
+func pointAt(x, y float64, a input.Action) Input {
+	in := press(a)
+	in.Pointer, in.PointerX, in.PointerY = true, x, y
+	return in
+}
+
+func rows(n int) []Rect {
+	s := VStack(Rect{X: 100, Y: 100, W: 200, H: 300}, 4)
+	out := make([]Rect, n)
+	for i := range out {
+		out[i] = s.Row(20)
+	}
+	return out
+}
+
+type recorder struct {
+	texts []string
+}
+
+func (r *recorder) FillRect(Rect, color.Color) {}
+
+func (r *recorder) Text(s string, x, y, scale float64, c color.Color) {
+	r.texts = append(r.texts, s)
+}
+
+func TestFocusMovesThroughWidgets(t *testing.T) {
+	c := New(basicfont.Face7x13)
+	r := rows(3)
+	var hit [3]bool
+	buttons := func() {
+		hit = [3]bool{}
+		for i, name := range []string{"a", "b", "c"} {
+			hit[i] = c.Button(name, r[i], name)
+		}
+	}
+
+	run(c, Input{}, buttons)
+	if c.Focus() != "a" {
+		t.Fatalf("expected the first widget to take focus, got %q", c.Focus())
+	}
+	run(c, press(input.Down), buttons)
+	run(c, press(input.Down), buttons)
+	if c.Focus() != "c" {
+		t.Fatalf("expected Down twice to reach c, got %q", c.Focus())
+	}
+	run(c, press(input.Down), buttons)
+	if c.Focus() != "a" {
+		t.Fatalf("expected focus to wrap to a, got %q", c.Focus())
+	}
+	run(c, press(input.Up), buttons)
+	run(c, press(input.Confirm), buttons)
+	if hit != [3]bool{false, false, true} {
+		t.Fatalf("expected Confirm to press c only, got %v", hit)
+	}
+	if c.Pressed(input.Confirm) {
+		t.Fatalf("expected the button to use up Confirm")
+	}
+}
+
+func TestPointerFocusesAndClicks(t *testing.T) {
+	c := New(basicfont.Face7x13)
+	r := rows(2)
+	var hit bool
+	buttons := func() {
+		c.Button("a", r[0], "a")
+		hit = c.Button("b", r[1], "b")
+	}
+
+	run(c, Input{}, buttons)
+	run(c, pointAt(150, r[1].Y+5, 0), buttons)
+	if c.Focus() != "b" {
+		t.Fatalf("expected pointing at b to focus it, got %q", c.Focus())
+	}
+
+	// A resting pointer does not take focus back from the keyboard.
+	run(c, press(input.Up), buttons)
+	run(c, pointAt(150, r[1].Y+5, 0), buttons)
+	if c.Focus() != "a" {
+		t.Fatalf("expected a still pointer to leave focus on a, got %q", c.Focus())
+	}
+
+	run(c, pointAt(150, r[1].Y+5, input.Click), buttons)
+	if !hit || c.Focus() != "b" {
+		t.Fatalf("expected clicking b to press and focus it")
+	}
+}
+
+func TestSliderStepsAndDrags(t *testing.T) {
+	c := New(basicfont.Face7x13)
+	r := Rect{X: 0, Y: 0, W: 216, H: 20}
+	v := 0.5
+	slider := func() { c.Slider("s", r, "Volume", &v, 0, 1, 0.1) }
+
+	run(c, press(input.Right), slider)
+	if math.Abs(v-0.6) > 1e-9 {
+		t.Fatalf("expected Right to step to 0.6, got %v", v)
+	}
+	for range 10 {
+		run(c, press(input.Right), slider)
+	}
+	if v != 1 {
+		t.Fatalf("expected the slider to stop at its top, got %v", v)
+	}
+
+	// The track is the right half, less the padding: 108 to 208.
+	run(c, pointAt(130, 10, input.Click), slider)
+	if math.Abs(v-0.2) > 1e-9 {
+		t.Fatalf("expected clicking the track to set 0.2, got %v", v)
+	}
+	drag := pointAt(180, 10, 0)
+	drag.Held = input.Click
+	run(c, drag, slider)
+	if math.Abs(v-0.7) > 1e-9 {
+		t.Fatalf("expected dragging to follow the pointer to 0.7, got %v", v)
+	}
+	run(c, pointAt(130, 10, 0), slider)
+	if math.Abs(v-0.7) > 1e-9 {
+		t.Fatalf("expected letting go to stop the drag, got %v", v)
+	}
+}
+
+func TestDropdownGrabsNavigation(t *testing.T) {
+	c := New(basicfont.Face7x13)
+	r := rows(2)
+	options := []string{"one", "two", "three"}
+	sel := 0
+	var changed bool
+	widgets := func() {
+		changed = c.Dropdown("d", r[0], "Pick", options, &sel)
+		c.Button("b", r[1], "b")
+	}
+
+	run(c, press(input.Right), widgets)
+	if sel != 1 || !changed {
+		t.Fatalf("expected Right to step a closed dropdown, got %d", sel)
+	}
+
+	run(c, press(input.Confirm), widgets)
+	run(c, press(input.Down), widgets)
+	if c.Focus() != "d" {
+		t.Fatalf("expected an open dropdown to keep focus, got %q", c.Focus())
+	}
+	if sel != 1 {
+		t.Fatalf("expected moving through the options to leave the choice alone")
+	}
+	run(c, press(input.Confirm), widgets)
+	if sel != 2 || !changed {
+		t.Fatalf("expected Confirm to pick the highlighted option, got %d", sel)
+	}
+
+	// The options draw over the button below.
+	run(c, press(input.Confirm), widgets)
+	run(c, pointAt(150, r[0].Y+r[0].H*1.5, input.Click), widgets)
+	if sel != 0 {
+		t.Fatalf("expected clicking the first option to pick it, got %d", sel)
+	}
+	focus := c.Focus()
+	run(c, press(input.Down), widgets)
+	if c.Focus() == focus {
+		t.Fatalf("expected a closed dropdown to give up navigation")
+	}
+}
+
+func TestListKeepsSelectionInSight(t *testing.T) {
+	c := New(basicfont.Face7x13)
+	r := Rect{W: 200, H: 3 * c.RowHeight()}
+	items := []string{"0", "1", "2", "3", "4", "5"}
+	sel := 0
+	p := &recorder{}
+	list := func() { c.List("l", r, items, &sel) }
+
+	run(c, press(input.Confirm), list)
+	for range 4 {
+		run(c, press(input.Down), list)
+	}
+	if sel != 4 {
+		t.Fatalf("expected Down to move the selection to 4, got %d", sel)
+	}
+	c.Draw(p)
+	if want := []string{"2", "3", "4"}; len(p.texts) != 3 || p.texts[2] != "4" {
+		t.Fatalf("expected rows %v shown, got %v", want, p.texts)
+	}
+
+	in := pointAt(10, 5, 0)
+	in.Scroll = 5
+	run(c, in, list)
+	run(c, pointAt(10, 5, input.Click), list)
+	if sel != 0 {
+		t.Fatalf("expected scrolling up and clicking the top row to select 0, got %d", sel)
+	}
+}
+
+func TestListScrollAndClickInOneFrame(t *testing.T) {
+	c := New(basicfont.Face7x13)
+	r := Rect{W: 200, H: 3 * c.RowHeight()}
+	items := []string{"0", "1", "2", "3", "4", "5"}
+	sel := 3
+	list := func() { c.List("l", r, items, &sel) }
+
+	in := pointAt(10, 5, input.Click)
+	in.Scroll = 5
+	run(c, in, list)
+	if sel != 0 {
+		t.Fatalf("expected a click while scrolling past the top to select 0, got %d", sel)
+	}
+}
+
+func TestTextInputTakesKeys(t *testing.T) {
+	c := New(basicfont.Face7x13)
+	r := rows(2)
+	name := "ab"
+	var done, back bool
+	widgets := func() {
+		done = c.TextInput("t", r[0], "Name", &name, 4)
+		c.Button("b", r[1], "b")
+		back = c.Pressed(input.Back)
+	}
+
+	run(c, press(input.Confirm), widgets)
+	if !c.Typing() {
+		t.Fatalf("expected pressing the field to start typing")
+	}
+	run(c, Input{Chars: []rune("cde"), Frame: input.Frame{Pressed: input.Down}}, widgets)
+	if name != "abcd" || c.Focus() != "t" {
+		t.Fatalf("expected typing to fill the field to its limit and keep focus, got %q", name)
+	}
+	erase := press(input.Back)
+	erase.Erase = true
+	run(c, erase, widgets)
+	if name != "abc" || back || !c.Typing() {
+		t.Fatalf("expected Backspace to erase without leaving, got %q", name)
+	}
+	run(c, Input{Enter: true, Frame: input.Frame{Pressed: input.Confirm}}, widgets)
+	if !done || c.Typing() {
+		t.Fatalf("expected Enter to finish typing")
+	}
+
+	run(c, press(input.Confirm), widgets)
+	run(c, press(input.Back), widgets)
+	if c.Typing() || back {
+		t.Fatalf("expected Back to stop typing and go no further")
+	}
+	run(c, press(input.Back), widgets)
+	if !back {
+		t.Fatalf("expected Back to be left for the screen once typing is over")
+	}
+}
+
+func TestStackLaysOutRows(t *testing.T) {
+	r := Centered(screen, 200, StackHeight(3, 20, 10))
+	if r.Y != 200 || r.H != 80 {
+		t.Fatalf("expected an 80 tall stack centered at 200, got %+v", r)
+	}
+	s := VStack(r, 10)
+	s.Row(20)
+	s.Space(5)
+	if row := s.Row(20); row.Y != 235 || row.X != 220 {
+		t.Fatalf("expected the second row at (220, 235), got %+v", row)
+	}
+	if rest := s.Rest(); rest.Y != 265 || rest.H != 15 {
+		t.Fatalf("expected 15 left at 265, got %+v", rest)
+	}
+	cols := Rect{W: 100, H: 10}.Columns(2, 10)
+	if cols[1].X != 55 || cols[1].W != 45 {
+		t.Fatalf("expected the second column at 55, 45 wide, got %+v", cols[1])
+	}
+}
@@ -0,0 +1,345 @@
+package ui
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jdefrancesco/squares/internal/input"
+)
+
+// Panel fills r to set the widgets on it off from what is behind.
+func (c *Context) Panel(r Rect) {
+	c.fill(r, c.Style.Panel)
+}
+
+// Label draws s at the left of r.
+func (c *Context) Label(r Rect, s string) {
+	c.textIn(r, s, false, c.Style.Text)
+}
+
+// Hint draws s dimmed and centered in r.
+func (c *Context) Hint(r Rect, s string) {
+	c.textIn(r, s, true, c.Style.TextDim)
+}
+
+// Heading draws s centered in r at scale times the usual size, with a
+// drop shadow.
+func (c *Context) Heading(r Rect, s string, scale float64) {
+	w, h := c.TextWidth(s)*scale, c.lineHeight()*scale
+	x, y := r.X+(r.W-w)/2, r.Y+(r.H-h)/2
+	c.text(s, x+1, y+1, scale, c.Style.Shadow)
+	c.text(s, x, y, scale, c.Style.Text)
+}
+
+// Button draws a button and reports whether it was pressed.
+func (c *Context) Button(id string, r Rect, label string) bool {
+	focused, hovered := c.focusable(id, r)
+	pressed := c.activated(id, focused, hovered)
+	c.box(r, c.focus == id)
+	c.textIn(r, label, true, c.Style.Text)
+	return pressed
+}
+
+// Tab draws one of a row of tabs, lit while selected, and reports
+// whether it was pressed.
+func (c *Context) Tab(id string, r Rect, label string, selected bool) bool {
+	focused, hovered := c.focusable(id, r)
+	pressed := c.activated(id, focused, hovered)
+	switch {
+	case c.focus == id:
+		c.fill(r, c.Style.Focus)
+	case selected || pressed:
+		c.fill(r, c.Style.Track)
+	default:
+		c.fill(r, c.Style.Item)
+	}
+	c.textIn(r, label, true, c.Style.Text)
+	return pressed
+}
+
+// Toggle draws an on/off switch for *v. Pressing it, Left or Right flips
+// it. It reports whether *v changed.
+func (c *Context) Toggle(id string, r Rect, label string, v *bool) bool {
+	focused, hovered := c.focusable(id, r)
+	changed := c.activated(id, focused, hovered) || focused && (c.take(input.Left) || c.take(input.Right))
+	if changed {
+		*v = !*v
+	}
+
+	c.box(r, c.focus == id)
+	c.textIn(r, label, false, c.Style.Text)
+	state := c.Style.Off
+	if *v {
+		state = c.Style.On
+	}
+	c.valueIn(r, state)
+	return changed
+}
+
+// Slider draws a slider for *v between lo and hi. Left and Right move it
+// by step, and the pointer can drag it; values are kept to multiples of
+// step from lo. It reports whether *v changed.
+func (c *Context) Slider(id string, r Rect, label string, v *float64, lo, hi, step float64) bool {
+	focused, hovered := c.focusable(id, r)
+	track := Rect{X: r.X + r.W/2, Y: r.Y + r.H/2 - 2, W: r.W/2 - c.Style.Pad, H: 4}
+
+	old := *v
+	next := old
+	if hovered && c.take(input.Click) {
+		c.focus, c.drag = id, id
+	}
+	switch {
+	case c.drag == id && c.pointed && track.W > 0:
+		next = lo + (c.px-track.X)/track.W*(hi-lo)
+	case focused && c.take(input.Left):
+		next -= step
+	case focused && c.take(input.Right):
+		next += step
+	}
+	if next != old {
+		if step > 0 {
+			next = lo + math.Round((next-lo)/step)*step
+		}
+		*v = max(lo, min(next, hi))
+	}
+
+	c.box(r, c.focus == id)
+	c.textIn(r, label, false, c.Style.Text)
+	c.fill(track, c.Style.Track)
+	frac := 0.0
+	if hi > lo {
+		frac = max(0, min((*v-lo)/(hi-lo), 1))
+	}
+	filled := track
+	filled.W *= frac
+	c.fill(filled, c.Style.Text)
+	c.fill(Rect{X: filled.X + filled.W - 3, Y: r.Y + r.H/2 - 6, W: 6, H: 12}, c.Style.Text)
+	return *v != old
+}
+
+// Dropdown draws a choice of options, *sel being the one chosen. Pressing
+// it opens the options over what is below, to pick with Up, Down and
+// Confirm or the pointer; Back or clicking elsewhere closes it. While
+// closed, Left and Right step through the options. It reports whether
+// *sel changed.
+func (c *Context) Dropdown(id string, r Rect, label string, options []string, sel *int) bool {
+	focused, hovered := c.focusable(id, r)
+	n := len(options)
+	if n == 0 {
+		return false
+	}
+	old := *sel
+	if c.grab != id {
+		switch {
+		case c.activated(id, focused, hovered):
+			c.hold(id, false)
+			c.drops[id] = max(0, min(*sel, n-1))
+		case focused && c.take(input.Left):
+			*sel = cycle(*sel, -1, n)
+		case focused && c.take(input.Right):
+			*sel = cycle(*sel, 1, n)
+		}
+	}
+
+	c.box(r, c.focus == id)
+	c.textIn(r, label, false, c.Style.Text)
+	if *sel >= 0 && *sel < n {
+		c.valueIn(r, options[*sel]+" v")
+	}
+	if c.grab == id {
+		c.onTop(func() { c.dropOpen(id, r, options, sel) })
+	}
+	return *sel != old
+}
+
+// dropOpen runs an open dropdown's options, below r if they fit on the
+// screen and above it if not.
+func (c *Context) dropOpen(id string, r Rect, options []string, sel *int) {
+	n := len(options)
+	rows := Rect{X: r.X, Y: r.Y + r.H, W: r.W, H: float64(n) * r.H}
+	if rows.Y+rows.H > c.screen.Y+c.screen.H {
+		rows.Y = r.Y - rows.H
+	}
+	hl := c.drops[id]
+	over := c.pointed && rows.Contains(c.px, c.py)
+	if over && c.moved {
+		hl = int((c.py - rows.Y) / r.H)
+	}
+
+	switch {
+	case c.take(input.Up):
+		hl = cycle(hl, -1, n)
+	case c.take(input.Down):
+		hl = cycle(hl, 1, n)
+	case c.take(input.Click):
+		if over {
+			*sel = int((c.py - rows.Y) / r.H)
+		}
+		c.release()
+	case c.take(input.Confirm):
+		*sel = hl
+		c.release()
+	case c.take(input.Back):
+		c.release()
+	}
+	c.drops[id] = hl
+
+	c.fill(rows, c.Style.Popup)
+	for i, o := range options {
+		row := Rect{X: rows.X, Y: rows.Y + float64(i)*r.H, W: r.W, H: r.H}
+		if i == hl {
+			c.fill(row, c.Style.Focus)
+		}
+		c.textIn(row, o, false, c.Style.Text)
+	}
+}
+
+type listState struct {
+	// top is the first row shown; wheel is mouse wheel turned but not yet
+	// a whole row.
+	top   int
+	wheel float64
+}
+
+// List draws a scrolling list of items, *sel being the one selected.
+// Pressing Confirm on it hands it Up and Down to move the selection until
+// Confirm or Back; clicking a row selects it and the mouse wheel scrolls.
+// It reports whether *sel changed.
+func (c *Context) List(id string, r Rect, items []string, sel *int) bool {
+	focused, hovered := c.focusable(id, r)
+	st := c.lists[id]
+	if st == nil {
+		st = &listState{}
+		c.lists[id] = st
+	}
+	rowH := c.RowHeight()
+	shown := max(1, int(r.H/rowH))
+	n := len(items)
+
+	old := *sel
+	if c.grab == id {
+		switch {
+		case c.take(input.Up):
+			*sel = max(0, *sel-1)
+		case c.take(input.Down):
+			*sel = min(n-1, *sel+1)
+		case c.take(input.Confirm), c.take(input.Back):
+			c.release()
+		}
+	} else if focused && n > 0 && c.take(input.Confirm) {
+		c.hold(id, false)
+	}
+	if hovered {
+		st.wheel += c.in.Scroll
+		rows := int(st.wheel)
+		st.wheel -= float64(rows)
+		st.top = max(0, min(st.top-rows, n-shown))
+		if c.take(input.Click) {
+			c.focus = id
+			if i := st.top + int((c.py-r.Y)/rowH); i >= 0 && i < n {
+				*sel = i
+			}
+		}
+	}
+	if *sel != old {
+		// Keep the selection in sight.
+		st.top = min(st.top, *sel)
+		st.top = max(st.top, *sel-shown+1)
+	}
+	st.top = max(0, min(st.top, n-shown))
+
+	c.fill(r, c.Style.Item)
+	if c.focus == id {
+		c.fill(Rect{X: r.X, Y: r.Y, W: 2, H: r.H}, c.Style.Focus)
+	}
+	for i := st.top; i < min(n, st.top+shown); i++ {
+		row := Rect{X: r.X, Y: r.Y + float64(i-st.top)*rowH, W: r.W, H: rowH}
+		if i == *sel {
+			col := c.Style.Track
+			if c.grab == id {
+				col = c.Style.Focus
+			}
+			c.fill(row, col)
+		}
+		c.textIn(row, items[i], false, c.Style.Text)
+	}
+	if n > shown {
+		bar := Rect{X: r.X + r.W - 3, W: 3, H: r.H * float64(shown) / float64(n)}
+		bar.Y = r.Y + r.H*float64(st.top)/float64(n)
+		c.fill(bar, c.Style.TextDim)
+	}
+	return *sel != old
+}
+
+// TextInput draws a one-line text field editing *s in place, up to maxLen
+// characters. Pressing it starts typing, which takes every key until
+// Enter, Back or a click elsewhere; Backspace erases. It reports whether
+// typing was finished with Enter.
+func (c *Context) TextInput(id string, r Rect, label string, s *string, maxLen int) bool {
+	focused, hovered := c.focusable(id, r)
+	done := false
+	if c.grab != id {
+		if c.activated(id, focused, hovered) {
+			c.hold(id, true)
+		}
+	} else {
+		for _, ch := range c.in.Chars {
+			if unicode.IsPrint(ch) && utf8.RuneCountInString(*s) < maxLen {
+				*s += string(ch)
+			}
+		}
+		// Space and Enter are Confirm and Backspace is Back, but here
+		// they are for typing.
+		c.used |= input.Confirm
+		switch {
+		case c.in.Enter:
+			done = true
+			c.release()
+		case c.in.Erase:
+			c.used |= input.Back
+			if _, size := utf8.DecodeLastRuneInString(*s); size > 0 {
+				*s = (*s)[:len(*s)-size]
+			}
+		case c.in.Pressed.Has(input.Back):
+			c.used |= input.Back
+			c.release()
+		case c.in.Pressed.Has(input.Click):
+			c.used |= input.Click
+			if !r.Contains(c.px, c.py) {
+				c.release()
+			}
+		}
+	}
+
+	c.box(r, c.focus == id)
+	c.textIn(r, label, false, c.Style.Text)
+	v := *s
+	if c.grab == id {
+		v += "_"
+	}
+	c.valueIn(r, v)
+	return done
+}
+
+// valueIn draws s at the right of r.
+func (c *Context) valueIn(r Rect, s string) {
+	c.text(s, r.X+r.W-c.Style.Pad-c.TextWidth(s), r.Y+(r.H-c.lineHeight())/2, 1, c.Style.Text)
+}
+
+// onTop records what f draws to go over everything else this frame.
+func (c *Context) onTop(f func()) {
+	base := len(c.cmds)
+	f()
+	c.overlay = append(c.overlay, c.cmds[base:]...)
+	c.cmds = c.cmds[:base]
+}
+
+// cycle moves i by step around n choices. An i that is not one of them
+// starts over from the first.
+func cycle(i, step, n int) int {
+	if i < 0 || i >= n {
+		return 0
+	}
+	return ((i+step)%n + n) % n
+}